@@ -70,9 +70,15 @@ func main() {
 	}
 	logger.Info().Msgf("Create rule's output: %v", createRuleOutputMap)
 
-	// Streams Tweets in real-time based on a specific set of filter rules
+	// Streams Tweets in real-time based on a specific set of filter rules.
+	// `WithManualStart` keeps the stream from connecting in the background, so
+	// `Connect` can return the error the stream could not be established with.
 	logger.Info().Msgf("Streaming real time tweets")
-	stream := client.StreamTweets(twitter.StreamTweetsInput{})
+	stream := client.StreamTweets(twitter.StreamTweetsInput{}, twitter.WithManualStart())
+	if err := stream.Connect(); err != nil {
+		logger.Error().Err(err).Msg("Failed to connect to the stream")
+		os.Exit(1)
+	}
 
 	// Stream tweets for 5 seconds and then call`Stop` to stop streaming
 	go func() {
@@ -81,15 +87,8 @@ func main() {
 		stream.Stop()
 
 	}()
-	for message := range stream.MessageQueue {
-
-		streamOutput, ok := message.(*twitter.StreamTweetsOutput)
-		if !ok {
-			logger.Error().Msg("Failed to cast message to Tweet")
-		} else {
-			logger.Info().Msgf("Tweet ID: %s, text: %s", streamOutput.Data.ID, streamOutput.Data.Text)
-		}
-
+	for message := range stream.Tweets() {
+		logger.Info().Msgf("Tweet ID: %s, text: %s", message.Data.Data.ID, message.Data.Data.Text)
 	}
 
 	// Get rules
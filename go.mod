@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/rs/zerolog v1.26.1
 	github.com/stretchr/testify v1.7.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,154 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrConsumerKeyEmpty is emitted when the consumer key or secret of the
+	// app-only provider are not set.
+	ErrConsumerKeyEmpty = errors.New("EmptyConsumerKey: consumer key and consumer secret are required")
+)
+
+// An AppOnlyProvider retrieves an app-only bearer token by exchanging the
+// app's consumer key and secret at the OAuth 2.0 token endpoint, using the
+// client credentials grant.
+//
+// The token is cached by the Credentials and only exchanged again once the
+// credentials are expired, for example after the Twitter API rejected it.
+type AppOnlyProvider struct {
+	// The consumer key and secret of the app.
+	ConsumerKey    string
+	ConsumerSecret string
+
+	// The base endpoint of the token endpoints. Defaults to EndPoint.
+	Endpoint string
+
+	// The HTTP client to use when exchanging tokens. Defaults to
+	// `http.DefaultClient`.
+	HTTPClient *http.Client
+
+	m         sync.Mutex
+	retrieved bool
+}
+
+// NewAppOnlyCredentials returns a pointer to a new Credentials with the
+// app-only provider wrapped for the consumer key and secret. The bearer token
+// is revoked with the Credentials' Invalidate method.
+func NewAppOnlyCredentials(consumerKey, consumerSecret string) *Credentials {
+	return NewCredentialsFromProvider(&AppOnlyProvider{
+		ConsumerKey:    consumerKey,
+		ConsumerSecret: consumerSecret,
+	})
+}
+
+// Retrieve exchanges the consumer key and secret for a bearer token.
+func (p *AppOnlyProvider) Retrieve() (Value, error) {
+	return p.RetrieveWithContext(backgroundCtx)
+}
+
+// RetrieveWithContext exchanges the consumer key and secret for a bearer token.
+func (p *AppOnlyProvider) RetrieveWithContext(ctx context.Context) (Value, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	var body struct {
+		TokenType   string `json:"token_type"`
+		AccessToken string `json:"access_token"`
+	}
+	err := p.send(ctx, "oauth2/token", url.Values{"grant_type": {"client_credentials"}}, &body)
+	if err != nil {
+		return Value{}, err
+	}
+	if !strings.EqualFold(body.TokenType, "bearer") || body.AccessToken == "" {
+		return Value{}, &OAuth2Error{
+			HTTPStatusCode: http.StatusOK,
+			Code:           "unexpected_token_type",
+			Description:    "token endpoint did not return a bearer token",
+		}
+	}
+
+	p.retrieved = true
+	return Value{BearerToken: body.AccessToken}, nil
+}
+
+// IsExpired returns if the bearer token has not been retrieved yet. Bearer
+// tokens do not expire, they are only invalidated.
+func (p *AppOnlyProvider) IsExpired() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return !p.retrieved
+}
+
+// Invalidate revokes the bearer token, so it can no longer be used to sign
+// requests. Credentials wrapping the provider should be expired afterwards
+// to exchange a new token.
+func (p *AppOnlyProvider) Invalidate(ctx context.Context, bearerToken string) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	err := p.send(ctx, "oauth2/invalidate_token", url.Values{"access_token": {bearerToken}}, nil)
+	if err != nil {
+		return err
+	}
+	p.retrieved = false
+	return nil
+}
+
+// send posts the form to the token endpoint path, authenticated with the
+// consumer key and secret, and decodes the response into out.
+func (p *AppOnlyProvider) send(ctx context.Context, path string, form url.Values, out interface{}) error {
+	if p.ConsumerKey == "" || p.ConsumerSecret == "" {
+		return ErrConsumerKeyEmpty
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = EndPoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(endpoint, "/")+"/"+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	req.SetBasicAuth(url.QueryEscape(p.ConsumerKey), url.QueryEscape(p.ConsumerSecret))
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return NewRequestFailure(err, 0, "failed to send app-only token request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var body struct {
+			Errors []struct {
+				Code    int    `json:"code"`
+				Label   string `json:"label"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		oauth2Err := &OAuth2Error{HTTPStatusCode: resp.StatusCode}
+		if err := UnmarshalJSON(&body, resp.Body); err == nil && len(body.Errors) > 0 {
+			oauth2Err.Code = body.Errors[0].Label
+			oauth2Err.Description = body.Errors[0].Message
+		}
+		return oauth2Err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := UnmarshalJSON(out, resp.Body); err != nil {
+		return NewRequestFailure(err, resp.StatusCode, "Failed to decode app-only token response")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+func (suite *twitterClientSuite) newAppOnlyProvider() *AppOnlyProvider {
+	return &AppOnlyProvider{
+		ConsumerKey:    "consumer key",
+		ConsumerSecret: "consumer secret",
+		Endpoint:       suite.server.URL,
+	}
+}
+
+func (suite *twitterClientSuite) Test_AppOnlyProviderRetrieve() {
+	suite.mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		suite.Require().True(ok)
+		suite.Assert().Equal("consumer+key", user)
+		suite.Assert().Equal("consumer+secret", pass)
+		suite.Assert().Equal("application/x-www-form-urlencoded;charset=UTF-8", r.Header.Get("Content-Type"))
+		suite.Require().Nil(r.ParseForm())
+		suite.Assert().Equal("client_credentials", r.PostForm.Get("grant_type"))
+		fmt.Fprintf(w, `{"token_type": "bearer", "access_token": "AAAA%%2FAAA%%3DAAAAAAAA"}`)
+	})
+
+	p := suite.newAppOnlyProvider()
+	suite.Assert().True(p.IsExpired())
+
+	value, err := p.Retrieve()
+
+	suite.Require().Nil(err)
+	suite.Assert().Equal("AAAA%2FAAA%3DAAAAAAAA", value.BearerToken)
+	suite.Assert().False(p.IsExpired())
+}
+
+func (suite *twitterClientSuite) Test_AppOnlyProviderError() {
+	suite.mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"errors": [{"code": 99, "label": "authenticity_token_error", "message": "Unable to verify your credentials"}]}`)
+	})
+
+	_, err := suite.newAppOnlyProvider().Retrieve()
+
+	var oauth2Err *OAuth2Error
+	suite.Require().True(errors.As(err, &oauth2Err))
+	suite.Assert().Equal("authenticity_token_error", oauth2Err.Code)
+	suite.Assert().Equal("Unable to verify your credentials", oauth2Err.Message())
+	suite.Assert().Equal(http.StatusForbidden, oauth2Err.StatusCode())
+
+	_, err = (&AppOnlyProvider{}).Retrieve()
+	suite.Assert().Equal(ErrConsumerKeyEmpty, err)
+}
+
+func (suite *twitterClientSuite) Test_AppOnlyProviderInvalidate() {
+	issued := 0
+	suite.mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		fmt.Fprintf(w, `{"token_type": "bearer", "access_token": "token-%d"}`, issued)
+	})
+	invalidated := ""
+	suite.mux.HandleFunc("/oauth2/invalidate_token", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Nil(r.ParseForm())
+		invalidated = r.PostForm.Get("access_token")
+		fmt.Fprintf(w, `{"access_token": "%s"}`, invalidated)
+	})
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"title": "Unauthorized", "type": "about:blank", "status": 401, "detail": "Unauthorized"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	p := suite.newAppOnlyProvider()
+	creds := NewCredentialsFromProvider(p)
+	value, err := creds.Retrieve()
+	suite.Require().Nil(err)
+	suite.Assert().Equal("token-1", value.BearerToken)
+
+	suite.Require().Nil(creds.Invalidate(context.Background()))
+	suite.Assert().Equal("token-1", invalidated)
+	suite.Assert().True(p.IsExpired())
+
+	// Invalidating expired the credentials, so a new token is exchanged
+	// before the request is signed.
+	suite.client.Config.WithCredentials(creds)
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	suite.Assert().Nil(req.Send())
+	suite.Assert().Equal(2, issued)
+}
+
+func (suite *twitterClientSuite) Test_InvalidateNotSupported() {
+	creds := NewCredentials(Value{BearerToken: "TEST"})
+	suite.Assert().Equal(ErrInvalidateNotSupported, creds.Invalidate(context.Background()))
+}
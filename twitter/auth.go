@@ -0,0 +1,129 @@
+package twitter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AuthType is a method of authenticating requests to the Twitter API.
+type AuthType int8
+
+const (
+	// AuthAppOnly authenticates as the app with a bearer token.
+	AuthAppOnly AuthType = iota
+
+	// AuthOAuth1User authenticates on behalf of a user with OAuth 1.0a
+	// user context credentials.
+	AuthOAuth1User
+
+	// AuthOAuth2User authenticates on behalf of a user with an OAuth 2.0
+	// user context access token.
+	AuthOAuth2User
+)
+
+// String returns the name of the auth type.
+func (t AuthType) String() string {
+	switch t {
+	case AuthAppOnly:
+		return "OAuth 2.0 App-only"
+	case AuthOAuth1User:
+		return "OAuth 1.0a User Context"
+	case AuthOAuth2User:
+		return "OAuth 2.0 User Context"
+	}
+	return fmt.Sprintf("AuthType(%d)", int8(t))
+}
+
+// An AuthError is returned before a request is sent when the configured
+// credentials can not authenticate it, because none of the auth types the
+// endpoint supports is configured or the access token lacks required scopes.
+type AuthError struct {
+	// The name of the endpoint.
+	EndPointName string
+
+	// The auth types the endpoint supports.
+	AuthTypes []AuthType
+
+	// The OAuth 2.0 scopes the endpoint requires, but the access token was
+	// not granted.
+	MissingScopes []string
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *AuthError) Error() string {
+	if len(e.MissingScopes) > 0 {
+		return fmt.Sprintf("AuthError: %s requires the OAuth 2.0 scopes %s, which the access token was not granted",
+			e.EndPointName, strings.Join(e.MissingScopes, ", "))
+	}
+
+	names := make([]string, len(e.AuthTypes))
+	for i, t := range e.AuthTypes {
+		names[i] = t.String()
+	}
+	return fmt.Sprintf("AuthError: %s supports only %s, but no matching credentials are configured",
+		e.EndPointName, strings.Join(names, " or "))
+}
+
+// authorize picks the first auth type the endpoint supports which the
+// credentials value holds. OAuth 2.0 user context access tokens must have been
+// granted the scopes the endpoint requires, unless the granted scopes are
+// unknown. Endpoints without auth types accept any credentials.
+func authorize(endpoint *EndPointInfo, value Value) (AuthType, error) {
+	if endpoint == nil || len(endpoint.AuthTypes) == 0 {
+		switch {
+		case value.BearerToken != "":
+			return AuthAppOnly, nil
+		case value.OAuth2Token != nil:
+			return AuthOAuth2User, nil
+		case value.HasOAuth1():
+			return AuthOAuth1User, nil
+		}
+		return 0, ErrCredentialsEmpty
+	}
+
+	var missingScopes []string
+	for _, t := range endpoint.AuthTypes {
+		switch t {
+		case AuthAppOnly:
+			if value.BearerToken != "" {
+				return t, nil
+			}
+		case AuthOAuth1User:
+			if value.HasOAuth1() {
+				return t, nil
+			}
+		case AuthOAuth2User:
+			if value.OAuth2Token == nil {
+				continue
+			}
+			missingScopes = value.OAuth2Token.missingScopes(endpoint.Scopes)
+			if len(missingScopes) == 0 {
+				return t, nil
+			}
+		}
+	}
+
+	return 0, &AuthError{
+		EndPointName:  endpoint.Name,
+		AuthTypes:     endpoint.AuthTypes,
+		MissingScopes: missingScopes,
+	}
+}
+
+// signHTTPRequestWithAuth sets the Authorization header of the HTTP request
+// using the credentials of the auth type.
+func signHTTPRequestWithAuth(req *http.Request, value Value, authType AuthType) error {
+	switch authType {
+	case AuthAppOnly:
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", value.BearerToken))
+		return nil
+	case AuthOAuth2User:
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", value.OAuth2Token.AccessToken))
+		return nil
+	case AuthOAuth1User:
+		return signOAuth1(req, value)
+	}
+	return ErrCredentialsEmpty
+}
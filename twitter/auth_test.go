@@ -0,0 +1,112 @@
+package twitter
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	bearer := Value{BearerToken: "bearer"}
+	oauth1 := Value{ConsumerKey: "key", ConsumerSecret: "secret", AccessToken: "token", AccessSecret: "token-secret"}
+	oauth2 := Value{OAuth2Token: &OAuth2Token{AccessToken: "access", Scope: "tweet.read users.read"}}
+	all := Value{
+		BearerToken:    bearer.BearerToken,
+		ConsumerKey:    oauth1.ConsumerKey,
+		ConsumerSecret: oauth1.ConsumerSecret,
+		AccessToken:    oauth1.AccessToken,
+		AccessSecret:   oauth1.AccessSecret,
+		OAuth2Token:    oauth2.OAuth2Token,
+	}
+
+	appOnly := &EndPointInfo{Name: "AppOnly", AuthTypes: []AuthType{AuthAppOnly}}
+	userContext := &EndPointInfo{
+		Name:      "UserContext",
+		AuthTypes: []AuthType{AuthOAuth2User, AuthOAuth1User},
+		Scopes:    []string{ScopeTweetRead, ScopeUsersRead},
+	}
+	bookmarks := &EndPointInfo{
+		Name:      "GetBookmarks",
+		AuthTypes: []AuthType{AuthOAuth2User},
+		Scopes:    []string{ScopeBookmarkRead},
+	}
+
+	cases := []struct {
+		endpoint *EndPointInfo
+		value    Value
+		expected AuthType
+		err      bool
+	}{
+		{&EndPointInfo{}, oauth2, AuthOAuth2User, false},
+		{&EndPointInfo{}, all, AuthAppOnly, false},
+		{appOnly, all, AuthAppOnly, false},
+		{appOnly, oauth1, 0, true},
+		{userContext, all, AuthOAuth2User, false},
+		{userContext, oauth1, AuthOAuth1User, false},
+		{userContext, bearer, 0, true},
+		{bookmarks, oauth2, 0, true},
+		{bookmarks, Value{OAuth2Token: &OAuth2Token{AccessToken: "access"}}, AuthOAuth2User, false},
+	}
+
+	for i, c := range cases {
+		authType, err := authorize(c.endpoint, c.value)
+		if c.err {
+			var authErr *AuthError
+			assert.True(t, errors.As(err, &authErr), "case %d", i)
+			continue
+		}
+		assert.Nil(t, err, "case %d", i)
+		assert.Equal(t, c.expected, authType, "case %d", i)
+	}
+
+	_, err := authorize(&EndPointInfo{}, Value{})
+	assert.Equal(t, ErrCredentialsEmpty, err)
+}
+
+func TestAuthErrorMessage(t *testing.T) {
+	err := &AuthError{EndPointName: "GetRules", AuthTypes: []AuthType{AuthAppOnly}}
+	assert.Equal(t, "AuthError: GetRules supports only OAuth 2.0 App-only, but no matching credentials are configured", err.Error())
+
+	err = &AuthError{EndPointName: "GetBookmarks", AuthTypes: []AuthType{AuthOAuth2User}, MissingScopes: []string{ScopeBookmarkRead}}
+	assert.Equal(t, "AuthError: GetBookmarks requires the OAuth 2.0 scopes bookmark.read, which the access token was not granted", err.Error())
+}
+
+func (suite *twitterClientSuite) Test_AuthMismatchFailsBeforeSending() {
+	sent := false
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		sent = true
+	})
+	suite.client.Config.WithCredentials(NewCredentials(Value{
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		AccessToken:    "token",
+		AccessSecret:   "token-secret",
+	}))
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	var authErr *AuthError
+	suite.Require().True(errors.As(err, &authErr))
+	suite.Assert().Equal(getRules, authErr.EndPointName)
+	suite.Assert().False(sent)
+}
+
+func (suite *twitterClientSuite) Test_AuthPicksMatchingCredentials() {
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().True(strings.HasPrefix(r.Header.Get("Authorization"), "OAuth "))
+		w.Write([]byte(`{"data": {}}`))
+	})
+	suite.client.Config.WithCredentials(NewCredentials(Value{
+		BearerToken:    "bearer",
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		AccessToken:    "token",
+		AccessSecret:   "token-secret",
+	}))
+
+	suite.Assert().Nil(suite.newUserContextRequest().Send())
+}
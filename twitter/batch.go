@@ -0,0 +1,237 @@
+package twitter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is the number of requests a BatchExecutor sends at
+// once when its concurrency is not set.
+const DefaultBatchConcurrency = 8
+
+// Priority is the lane requests wait in for a BatchExecutor to send them.
+type Priority int8
+
+const (
+	// PriorityBackground is the lane of bulk and background jobs.
+	PriorityBackground Priority = iota
+
+	// PriorityInteractive is the lane of interactive calls. Waiting
+	// interactive requests are always sent before waiting background ones.
+	PriorityInteractive
+)
+
+// A BatchResult is the result of a request sent by a BatchExecutor.
+type BatchResult struct {
+	// The request, whose Data holds the output.
+	Request *Request
+
+	// The error the request failed with, if any.
+	Error error
+}
+
+// A BatchExecutor sends many requests with bounded concurrency, sharing the
+// rate limit budget of each endpoint between them.
+//
+// A request first waits for a free slot in its priority lane, then the rate
+// limit last reported for its endpoint is checked. If the requests already in
+// flight use up the remaining budget of the window, the request gives up its
+// slot and waits for the window to reset before it queues again. The rate limits are
+// read from the tracker requests report to, which is the client's RateLimits
+// for requests made with the client.
+//
+// A BatchExecutor is safe to use concurrently, and its concurrency and rate
+// limit budgets are shared between all calls to Execute.
+type BatchExecutor struct {
+	// The maximum number of requests sent at once. Defaults to
+	// DefaultBatchConcurrency.
+	Concurrency int
+
+	// The tracker the rate limit budgets are derived from.
+	RateLimits *RateLimitTracker
+
+	m        sync.Mutex
+	inFlight int
+	waiters  [PriorityInteractive + 1][]chan struct{}
+	budgets  map[string]int
+}
+
+// NewBatchExecutor returns a pointer to a new BatchExecutor which sends up to
+// concurrency requests at once, within the rate limits tracked by the client.
+func (c *Client) NewBatchExecutor(concurrency int) *BatchExecutor {
+	return &BatchExecutor{
+		Concurrency: concurrency,
+		RateLimits:  c.RateLimits,
+	}
+}
+
+// Execute sends the requests in the background lane, and returns their
+// results in the order of the requests once all of them completed.
+func (e *BatchExecutor) Execute(ctx context.Context, reqs []*Request) []BatchResult {
+	return e.ExecuteWithPriority(ctx, PriorityBackground, reqs)
+}
+
+// ExecuteWithPriority sends the requests in the priority lane, and returns
+// their results in the order of the requests once all of them completed.
+// Requests which could not be sent before the context was done fail with the
+// context's error.
+//
+// The requests are sent by a pool of at most Concurrency workers, so large
+// batches do not start a goroutine per request.
+func (e *BatchExecutor) ExecuteWithPriority(ctx context.Context, priority Priority, reqs []*Request) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+
+	workers := e.concurrency()
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = BatchResult{
+					Request: reqs[i],
+					Error:   e.send(ctx, priority, reqs[i]),
+				}
+			}
+		}()
+	}
+	for i := range reqs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// send waits for a free slot and the endpoint's rate limit budget, and sends
+// the request. The slot is given up while waiting for budget, so requests of
+// other endpoints and interactive requests are not held up.
+func (e *BatchExecutor) send(ctx context.Context, priority Priority, req *Request) error {
+	if req.Error != nil {
+		return req.Error
+	}
+
+	var name string
+	if req.EndPointInfo != nil {
+		name = req.EndPointInfo.Name
+	}
+	for {
+		if err := e.acquire(ctx, priority); err != nil {
+			return err
+		}
+		wait, ok := e.reserve(name)
+		if ok {
+			break
+		}
+		e.release()
+
+		if err := sleepWithContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+	defer e.release()
+	defer e.unreserve(name)
+
+	return req.SendWithContext(ctx)
+}
+
+// reserve reserves budget for one more request to the endpoint if the rate
+// limit last reported for it leaves budget besides the ones already reserved.
+// Otherwise it returns how long to wait for the window to reset.
+func (e *BatchExecutor) reserve(name string) (time.Duration, bool) {
+	limit, ok := e.RateLimits.Get(name)
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	if !ok || limit.UntilReset() <= 0 || limit.Remaining > e.budgets[name] {
+		if e.budgets == nil {
+			e.budgets = make(map[string]int)
+		}
+		e.budgets[name]++
+		return 0, true
+	}
+	return limit.UntilReset() + rateLimitResetPadding, false
+}
+
+// unreserve releases the budget reserved for the request, which is reflected
+// in the rate limit reported by its response by now.
+func (e *BatchExecutor) unreserve(name string) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.budgets[name]--
+}
+
+// acquire waits for a free slot. Slots are handed to waiting interactive
+// requests first, and to waiting requests of the same lane in FIFO order.
+func (e *BatchExecutor) acquire(ctx context.Context, priority Priority) error {
+	e.m.Lock()
+	if e.inFlight < e.concurrency() && len(e.waiters[PriorityInteractive]) == 0 &&
+		(priority == PriorityInteractive || len(e.waiters[PriorityBackground]) == 0) {
+		e.inFlight++
+		e.m.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	e.waiters[priority] = append(e.waiters[priority], ready)
+	e.m.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		e.m.Lock()
+		defer e.m.Unlock()
+		select {
+		case <-ready:
+			// The slot was handed over concurrently, pass it on.
+			e.releaseLocked()
+		default:
+			e.removeWaiterLocked(priority, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a sent request.
+func (e *BatchExecutor) release() {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.releaseLocked()
+}
+
+// releaseLocked hands the slot to the next waiting request, or frees it.
+func (e *BatchExecutor) releaseLocked() {
+	for p := PriorityInteractive; p >= PriorityBackground; p-- {
+		if len(e.waiters[p]) > 0 {
+			ready := e.waiters[p][0]
+			e.waiters[p] = e.waiters[p][1:]
+			close(ready)
+			return
+		}
+	}
+	e.inFlight--
+}
+
+func (e *BatchExecutor) removeWaiterLocked(priority Priority, ready chan struct{}) {
+	waiters := e.waiters[priority]
+	for i, w := range waiters {
+		if w == ready {
+			e.waiters[priority] = append(waiters[:i:i], waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *BatchExecutor) concurrency() int {
+	if e.Concurrency > 0 {
+		return e.Concurrency
+	}
+	return DefaultBatchConcurrency
+}
@@ -0,0 +1,218 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_BatchExecutorResultsInOrder() {
+	var inFlight, maxInFlight int32
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		id := r.URL.Query().Get("ids")
+		if id == "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"title": "Invalid Request", "detail": "One or more parameters to your request was invalid.", "type": "https://api.twitter.com/2/problems/invalid-request"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": [{"id": "%s", "value": "rule %s"}]}`, id, id)
+	})
+
+	var reqs []*Request
+	for i := 0; i < 10; i++ {
+		req, _ := suite.client.GetRules(&GetRulesInput{IDs: []string{fmt.Sprint(i)}})
+		reqs = append(reqs, req)
+	}
+	results := suite.client.NewBatchExecutor(3).Execute(context.Background(), reqs)
+
+	suite.Require().Equal(10, len(results))
+	for i, result := range results {
+		suite.Assert().Same(reqs[i], result.Request)
+		if i == 3 {
+			var apiErr *APIError
+			suite.Require().True(errors.As(result.Error, &apiErr))
+			suite.Assert().Equal(http.StatusBadRequest, apiErr.StatusCode())
+			continue
+		}
+		suite.Assert().Nil(result.Error)
+		suite.Assert().Equal(fmt.Sprint(i), result.Request.Data.(*GetRulesOutput).Data[0].ID.String())
+	}
+	suite.Assert().True(maxInFlight <= 3, "sent %d requests at once", maxInFlight)
+}
+
+func (suite *twitterClientSuite) Test_BatchExecutorWaitsForRateLimitBudget() {
+	padding := rateLimitResetPadding
+	rateLimitResetPadding = 0
+	defer func() { rateLimitResetPadding = padding }()
+
+	reset := time.Now().Add(200 * time.Millisecond)
+	var m sync.Mutex
+	var sent []time.Time
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		sent = append(sent, time.Now())
+		m.Unlock()
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+	suite.client.RateLimits.Set(getRules, RateLimit{Limit: 450, Remaining: 1, Reset: reset})
+
+	var reqs []*Request
+	for i := 0; i < 3; i++ {
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		reqs = append(reqs, req)
+	}
+	results := suite.client.NewBatchExecutor(3).Execute(context.Background(), reqs)
+
+	for _, result := range results {
+		suite.Assert().Nil(result.Error)
+	}
+	early := 0
+	for _, t := range sent {
+		if t.Before(reset) {
+			early++
+		}
+	}
+	suite.Assert().Equal(1, early)
+}
+
+func (suite *twitterClientSuite) Test_BatchExecutorPriorityLane() {
+	release := make(chan struct{})
+	var m sync.Mutex
+	var order []string
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("ids")
+		if id == "blocker" {
+			<-release
+		}
+		m.Lock()
+		order = append(order, id)
+		m.Unlock()
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+	newReq := func(id string) *Request {
+		req, _ := suite.client.GetRules(&GetRulesInput{IDs: []string{id}})
+		return req
+	}
+	executor := suite.client.NewBatchExecutor(1)
+	waiting := func(priority Priority, n int) func() bool {
+		return func() bool {
+			executor.m.Lock()
+			defer executor.m.Unlock()
+			return len(executor.waiters[priority]) == n
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		executor.Execute(context.Background(), []*Request{newReq("blocker")})
+	}()
+	suite.Require().Eventually(func() bool {
+		executor.m.Lock()
+		defer executor.m.Unlock()
+		return executor.inFlight == 1
+	}, time.Second, time.Millisecond)
+	go func() {
+		defer wg.Done()
+		executor.Execute(context.Background(), []*Request{newReq("background1"), newReq("background2")})
+	}()
+	// The batch is sent by a single worker, as the concurrency is 1.
+	suite.Require().Eventually(waiting(PriorityBackground, 1), time.Second, time.Millisecond)
+	go func() {
+		defer wg.Done()
+		executor.ExecuteWithPriority(context.Background(), PriorityInteractive, []*Request{newReq("interactive")})
+	}()
+	suite.Require().Eventually(waiting(PriorityInteractive, 1), time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	suite.Require().Equal(4, len(order))
+	suite.Assert().Equal([]string{"blocker", "interactive"}, order[:2])
+}
+
+func (suite *twitterClientSuite) Test_BatchExecutorContextCanceled() {
+	suite.client.RateLimits.Set(getRules, RateLimit{Limit: 450, Remaining: 0, Reset: time.Now().Add(time.Hour)})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results := suite.client.NewBatchExecutor(1).Execute(ctx, []*Request{req})
+
+	suite.Assert().Equal(context.DeadlineExceeded, results[0].Error)
+}
+
+func (suite *twitterClientSuite) Test_BatchExecutorReleasesSlotWhileWaitingForBudget() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+	reset := time.Now().Add(time.Hour)
+	suite.client.RateLimits.Set(getRules, RateLimit{Limit: 450, Remaining: 0, Reset: reset})
+	executor := suite.client.NewBatchExecutor(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []BatchResult)
+	go func() {
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		done <- executor.Execute(ctx, []*Request{req})
+	}()
+
+	// The rate limited background request does not hold the only slot.
+	req, _ := suite.client.ValidateRules(&ValidateRulesInput{Add: []Rule{{Value: "dog has:images"}}})
+	results := executor.ExecuteWithPriority(context.Background(), PriorityInteractive, []*Request{req})
+	suite.Assert().Nil(results[0].Error)
+	suite.Assert().True(time.Now().Before(reset))
+
+	cancel()
+	results = <-done
+	suite.Assert().Equal(context.Canceled, results[0].Error)
+}
+
+func (suite *twitterClientSuite) Test_BatchExecutorBoundsWorkers() {
+	release := make(chan struct{})
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+	executor := suite.client.NewBatchExecutor(2)
+
+	var reqs []*Request
+	for i := 0; i < 50; i++ {
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		reqs = append(reqs, req)
+	}
+	done := make(chan []BatchResult)
+	go func() {
+		done <- executor.Execute(context.Background(), reqs)
+	}()
+
+	suite.Require().Eventually(func() bool {
+		executor.m.Lock()
+		defer executor.m.Unlock()
+		return executor.inFlight == 2
+	}, time.Second, time.Millisecond)
+	// Only the workers wait for slots, not a goroutine per request.
+	executor.m.Lock()
+	suite.Assert().Equal(0, len(executor.waiters[PriorityBackground]))
+	executor.m.Unlock()
+
+	close(release)
+	for _, result := range <-done {
+		suite.Assert().Nil(result.Error)
+	}
+}
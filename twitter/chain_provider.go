@@ -0,0 +1,98 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoValidProvidersFoundInChain is returned when there are no valid
+	// providers in the ChainProvider.
+	ErrNoValidProvidersFoundInChain = errors.New("NoCredentialProviders: no valid providers in chain")
+)
+
+// A ChainProvider will search for a provider which returns credentials
+// and cache that provider until Retrieve is called again.
+//
+// The ChainProvider provides a way of chaining multiple providers together
+// which will pick the first available using priority order of the Providers
+// in the list.
+//
+// If none of the Providers retrieve valid credentials Value, ChainProvider's
+// Retrieve() will return the error ErrNoValidProvidersFoundInChain.
+//
+// If a Provider is found which returns valid credentials Value ChainProvider
+// will cache that Provider for all calls to IsExpired(), until Retrieve is
+// called again.
+//
+// Example of ChainProvider to be used with an EnvProvider and FileProvider.
+// In this example EnvProvider will first check if any credentials are
+// available via the environment variables. If there are none ChainProvider
+// will check the next Provider in the list, FileProvider in this case.
+//
+//	creds := twitter.NewChainCredentials(
+//	    []twitter.Provider{
+//	        &twitter.EnvProvider{},
+//	        &twitter.FileProvider{Profile: "bot"},
+//	    })
+//
+//	client := twitter.NewClient(twitter.NewConfig().WithCredentials(creds))
+type ChainProvider struct {
+	Providers []Provider
+
+	// Include the errors of every provider in the returned error, instead of
+	// only ErrNoValidProvidersFoundInChain.
+	VerboseErrors bool
+
+	curr Provider
+}
+
+// NewChainCredentials returns a pointer to a new Credentials object
+// wrapping a chain of providers.
+func NewChainCredentials(providers []Provider) *Credentials {
+	return NewCredentialsFromProvider(&ChainProvider{
+		Providers: append([]Provider{}, providers...),
+	})
+}
+
+// Retrieve returns the credentials value or error if no provider returned
+// without error.
+//
+// If a provider is found it will be cached and any calls to IsExpired()
+// will return the expired state of the cached provider.
+func (c *ChainProvider) Retrieve() (Value, error) {
+	return c.RetrieveWithContext(backgroundCtx)
+}
+
+// RetrieveWithContext returns the credentials value or error if no provider
+// returned without error. Providers which support a Context are passed ctx.
+func (c *ChainProvider) RetrieveWithContext(ctx context.Context) (Value, error) {
+	var errs []string
+	for _, p := range c.Providers {
+		creds, err := retrieveFromProvider(ctx, p)
+		if err == nil {
+			c.curr = p
+			return creds, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	c.curr = nil
+
+	err := ErrNoValidProvidersFoundInChain
+	if c.VerboseErrors && len(errs) > 0 {
+		err = fmt.Errorf("%w: %s", ErrNoValidProvidersFoundInChain, strings.Join(errs, "; "))
+	}
+	return Value{}, err
+}
+
+// IsExpired will returned the expired state of the currently cached provider
+// if there is one. If there is no current provider, true will be returned.
+func (c *ChainProvider) IsExpired() bool {
+	if c.curr != nil {
+		return c.curr.IsExpired()
+	}
+
+	return true
+}
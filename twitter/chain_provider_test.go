@@ -0,0 +1,89 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubProvider struct {
+	value   Value
+	err     error
+	expired bool
+	calls   int
+}
+
+func (s *stubProvider) Retrieve() (Value, error) {
+	s.calls++
+	s.expired = false
+	return s.value, s.err
+}
+
+func (s *stubProvider) IsExpired() bool {
+	return s.expired
+}
+
+func TestChainProviderRetrieve(t *testing.T) {
+	first := &stubProvider{err: errors.New("first provider failed")}
+	second := &stubProvider{value: Value{BearerToken: "second"}}
+	third := &stubProvider{value: Value{BearerToken: "third"}}
+	p := &ChainProvider{Providers: []Provider{first, second, third}}
+
+	value, err := p.Retrieve()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "second", value.BearerToken)
+	assert.Equal(t, 0, third.calls)
+	assert.False(t, p.IsExpired())
+	second.expired = true
+	assert.True(t, p.IsExpired())
+}
+
+func TestChainProviderNoValidProviders(t *testing.T) {
+	p := &ChainProvider{
+		Providers: []Provider{
+			&stubProvider{err: errors.New("first provider failed")},
+			&StaticProvider{},
+		},
+	}
+
+	_, err := p.Retrieve()
+	assert.Equal(t, ErrNoValidProvidersFoundInChain, err)
+	assert.True(t, p.IsExpired())
+
+	p.VerboseErrors = true
+	_, err = p.Retrieve()
+	assert.True(t, errors.Is(err, ErrNoValidProvidersFoundInChain))
+	assert.Contains(t, err.Error(), "first provider failed")
+	assert.Contains(t, err.Error(), ErrStaticCredentialsEmpty.Error())
+}
+
+func (suite *twitterClientSuite) Test_ProviderCredentialsRotate() {
+	provider := &stubProvider{value: Value{BearerToken: "first"}}
+	suite.client.Config.WithCredentials(NewChainCredentials([]Provider{
+		&StaticProvider{},
+		provider,
+	}))
+
+	var authorization []string
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	send := func() {
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		suite.Require().Nil(req.Send())
+	}
+	send()
+	send()
+	provider.value = Value{BearerToken: "second"}
+	provider.expired = true
+	send()
+
+	suite.Assert().Equal([]string{"Bearer first", "Bearer first", "Bearer second"}, authorization)
+	suite.Assert().Equal(2, provider.calls)
+}
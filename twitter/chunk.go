@@ -0,0 +1,231 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// MaxIDsPerRequest is the maximum number of IDs lookup endpoints accept in a
+// single request.
+const MaxIDsPerRequest = 100
+
+// ErrChunkOutputMismatch is returned by a ChunkedLookup when the output of a
+// chunk is not of the type of the output the chunks are merged into.
+var ErrChunkOutputMismatch = errors.New("ChunkOutputMismatch: chunk output type does not match the merged output type")
+
+// A ChunkedLookup sends an ID-list operation in chunks of compliant size, and
+// merges the outputs of the chunks into a single output.
+//
+// The Data slice, the slices of the Includes struct and the Errors slice of
+// the chunk outputs are appended to the output's in the order of the chunks,
+// so the merged output is the same whether or not chunks were sent in
+// parallel. Included objects are deduplicated by ID and the Meta result counts
+// are added up. Other fields, such as the rest of Meta, are taken from the
+// first chunk.
+type ChunkedLookup struct {
+	// The IDs to look up.
+	IDs []string
+
+	// The number of IDs per request. Defaults to MaxIDsPerRequest.
+	ChunkSize int
+
+	// The executor to send chunks in parallel with. Chunks are sent one after
+	// the other when nil.
+	Executor *BatchExecutor
+
+	// Function to return a Request value for a chunk of IDs. The Data of the
+	// request must be a pointer of the same type as the merged output.
+	NewRequest func(ids []string) *Request
+}
+
+// A ChunkError is returned when a chunk of a ChunkedLookup failed. The output
+// holds the merged outputs of the chunks which succeeded.
+type ChunkError struct {
+	// The IDs of the chunk which failed.
+	IDs []string
+
+	// The error the chunk failed with.
+	Err error
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *ChunkError) Error() string {
+	return SprintError(fmt.Sprintf("ChunkError: lookup of %d IDs failed", len(e.IDs)), "", e.Err)
+}
+
+// Unwrap returns the error the chunk failed with.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// Send sends the chunks and merges their outputs into output, which must be a
+// pointer to the output type of the operation. The error of the first chunk
+// which failed is returned, after the other chunks were merged.
+func (l *ChunkedLookup) Send(ctx context.Context, output interface{}) error {
+	chunks := chunkIDs(l.IDs, l.ChunkSize)
+	reqs := make([]*Request, len(chunks))
+	for i, ids := range chunks {
+		reqs[i] = l.NewRequest(ids)
+	}
+
+	var results []BatchResult
+	if l.Executor != nil {
+		results = l.Executor.Execute(ctx, reqs)
+	} else {
+		results = make([]BatchResult, len(reqs))
+		for i, req := range reqs {
+			results[i] = BatchResult{Request: req, Error: req.SendWithContext(ctx)}
+		}
+	}
+
+	var firstErr error
+	merged := false
+	for i, result := range results {
+		err := result.Error
+		if err == nil {
+			err = mergeOutput(output, result.Request.Data, !merged)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = &ChunkError{IDs: chunks[i], Err: err}
+			}
+			continue
+		}
+		merged = true
+	}
+	return firstErr
+}
+
+// sendHandler returns a Send handler which looks up the IDs in chunks
+// instead of sending the request, and merges the outputs of the chunks into
+// the request's Data. The request's other handler lists are cleared, as every
+// chunk is signed, sent, unmarshaled and retried as a request of its own.
+func (l *ChunkedLookup) sendHandler() HandlerFunction {
+	return HandlerFunction{
+		Name: "ChunkedLookupSendHandler",
+		Fn: func(r *Request) {
+			r.Error = l.Send(r.Context(), r.Data)
+		},
+	}
+}
+
+// sendInChunks makes the request look up the IDs in chunks once it is sent.
+func (l *ChunkedLookup) sendInChunks(r *Request) {
+	r.Handlers.Clear()
+	r.Handlers.Send.PushBackNamed(l.sendHandler())
+}
+
+// chunkIDs splits the IDs into chunks of at most size IDs.
+func chunkIDs(ids []string, size int) [][]string {
+	if size <= 0 {
+		size = MaxIDsPerRequest
+	}
+
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
+
+// mergeOutput appends the Data, Includes and Errors of the chunk output to
+// the output, and adds up the result counts of their Meta. Every other field
+// is copied from the chunk output if first is set. The slices are copied, so
+// the output does not share memory with the chunk outputs.
+//
+// ErrChunkOutputMismatch is returned if the output and the chunk output are
+// not pointers to the same struct type.
+func mergeOutput(output, chunk interface{}, first bool) error {
+	dst := reflect.ValueOf(output)
+	src := reflect.ValueOf(chunk)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() || dst.Type() != src.Type() ||
+		src.IsNil() || dst.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w, got %T for %T", ErrChunkOutputMismatch, chunk, output)
+	}
+	dst, src = dst.Elem(), src.Elem()
+	if first {
+		dst.Set(src)
+		resetField(dst.FieldByName("Data"))
+		resetField(dst.FieldByName("Errors"))
+		if includes := dst.FieldByName("Includes"); includes.IsValid() && includes.Kind() == reflect.Struct {
+			for i := 0; i < includes.NumField(); i++ {
+				resetField(includes.Field(i))
+			}
+		}
+	} else if meta := dst.FieldByName("Meta"); meta.IsValid() && meta.Kind() == reflect.Struct {
+		if count := meta.FieldByName("ResultCount"); count.IsValid() && count.Kind() == reflect.Int {
+			count.SetInt(count.Int() + src.FieldByName("Meta").FieldByName("ResultCount").Int())
+		}
+	}
+
+	appendField(dst.FieldByName("Data"), src.FieldByName("Data"))
+	appendField(dst.FieldByName("Errors"), src.FieldByName("Errors"))
+	if includes := dst.FieldByName("Includes"); includes.IsValid() && includes.Kind() == reflect.Struct {
+		for i := 0; i < includes.NumField(); i++ {
+			appendUniqueField(includes.Field(i), src.FieldByName("Includes").Field(i))
+		}
+	}
+	return nil
+}
+
+// resetField sets the slice to nil, so it is copied when appended to.
+func resetField(v reflect.Value) {
+	if v.IsValid() && v.Kind() == reflect.Slice && v.CanSet() {
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// appendField appends the src slice to the dst slice, if both are valid.
+func appendField(dst, src reflect.Value) {
+	if !dst.IsValid() || dst.Kind() != reflect.Slice || !dst.CanSet() {
+		return
+	}
+	dst.Set(reflect.AppendSlice(dst, src))
+}
+
+// appendUniqueField appends the elements of the src slice to the dst slice
+// whose ID is not in the dst slice yet. Objects expanded for several chunks,
+// such as the author of Tweets in different chunks, are only included once.
+func appendUniqueField(dst, src reflect.Value) {
+	if !dst.IsValid() || dst.Kind() != reflect.Slice || !dst.CanSet() {
+		return
+	}
+
+	seen := make(map[string]bool, dst.Len())
+	for i := 0; i < dst.Len(); i++ {
+		if id, ok := objectID(dst.Index(i)); ok {
+			seen[id] = true
+		}
+	}
+	for i := 0; i < src.Len(); i++ {
+		elem := src.Index(i)
+		if id, ok := objectID(elem); ok {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		dst.Set(reflect.Append(dst, elem))
+	}
+}
+
+// objectID returns the ID of an expanded object, which is the media key of
+// media objects. False is returned if the object has no ID.
+func objectID(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+	for _, name := range []string{"ID", "MediaKey"} {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,181 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkIDs(t *testing.T) {
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+
+	chunks := chunkIDs(ids, 0)
+
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, 100, len(chunks[0]))
+	assert.Equal(t, 100, len(chunks[1]))
+	assert.Equal(t, 50, len(chunks[2]))
+	assert.Equal(t, "100", chunks[1][0])
+	assert.Equal(t, 0, len(chunkIDs(nil, 10)))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, chunkIDs([]string{"a", "b", "c"}, 2))
+}
+
+func TestMergeOutput(t *testing.T) {
+	first := &GetRulesOutput{
+		Data:   []Rule{{ID: "1"}},
+		Meta:   GetRulesOutputMeta{PaginationMeta: PaginationMeta{ResultCount: 1}},
+		Errors: []PartialError{{Value: "2"}},
+	}
+	output := &GetRulesOutput{}
+	mergeOutput(output, first, true)
+	mergeOutput(output, &GetRulesOutput{
+		Data:   []Rule{{ID: "3"}, {ID: "4"}},
+		Meta:   GetRulesOutputMeta{PaginationMeta: PaginationMeta{ResultCount: 2}},
+		Errors: []PartialError{{Value: "5"}},
+	}, false)
+
+	assert.Equal(t, []Rule{{ID: "1"}, {ID: "3"}, {ID: "4"}}, output.Data)
+	assert.Equal(t, []PartialError{{Value: "2"}, {Value: "5"}}, output.Errors)
+	assert.Equal(t, 3, output.Meta.ResultCount)
+
+	// The first chunk's output is not modified through the merged output.
+	output.Data[0].ID = "modified"
+	assert.Equal(t, "1", first.Data[0].ID.String())
+	assert.Equal(t, 1, first.Meta.ResultCount)
+}
+
+func TestMergeOutputIncludes(t *testing.T) {
+	type lookupOutput struct {
+		Data     []Tweet
+		Includes Includes
+	}
+
+	output := &lookupOutput{}
+	mergeOutput(output, &lookupOutput{
+		Data:     []Tweet{{ID: "1", AuthorID: "10"}},
+		Includes: Includes{Users: []User{{ID: "10"}}, Media: []Media{{MediaKey: "3_1"}}},
+	}, true)
+	mergeOutput(output, &lookupOutput{
+		Data:     []Tweet{{ID: "2", AuthorID: "10"}, {ID: "3", AuthorID: "30"}},
+		Includes: Includes{Users: []User{{ID: "10"}, {ID: "30"}}, Media: []Media{{MediaKey: "3_1"}, {MediaKey: "3_2"}}},
+	}, false)
+
+	assert.Equal(t, 3, len(output.Data))
+	assert.Equal(t, []User{{ID: "10"}, {ID: "30"}}, output.Includes.Users)
+	assert.Equal(t, []Media{{MediaKey: "3_1"}, {MediaKey: "3_2"}}, output.Includes.Media)
+}
+
+func (suite *twitterClientSuite) handleRulesLookup() *int {
+	requests := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		suite.Assert().True(len(ids) <= MaxIDsPerRequest)
+
+		var data, errs []string
+		for _, id := range ids {
+			if id == "missing" {
+				errs = append(errs, `{"value": "missing", "detail": "Could not find rule", "title": "Not Found Error"}`)
+				continue
+			}
+			data = append(data, fmt.Sprintf(`{"id": "%s", "value": "rule %s"}`, id, id))
+		}
+		fmt.Fprintf(w, `{"data": [%s], "errors": [%s], "meta": {"sent": "2019-08-29T01:12:10.729Z"}}`,
+			strings.Join(data, ","), strings.Join(errs, ","))
+	})
+	return &requests
+}
+
+func (suite *twitterClientSuite) Test_GetRulesChunked() {
+	requests := suite.handleRulesLookup()
+
+	ids := make([]string, 230)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	ids[150] = "missing"
+
+	for _, executor := range []*BatchExecutor{nil, suite.client.NewBatchExecutor(3)} {
+		*requests = 0
+		output, err := suite.client.GetRulesChunked(context.Background(), &GetRulesInput{IDs: ids}, executor)
+
+		suite.Require().Nil(err)
+		suite.Assert().Equal(3, *requests)
+		suite.Require().Equal(229, len(output.Data))
+		for i, rule := range output.Data {
+			expected := i
+			if i >= 150 {
+				expected++
+			}
+			suite.Assert().Equal(fmt.Sprint(expected), rule.ID.String())
+		}
+		suite.Assert().Equal(1, len(output.Errors))
+		suite.Assert().Equal("missing", output.Errors[0].Value)
+		suite.Assert().False(output.Meta.Sent.IsZero())
+	}
+}
+
+func (suite *twitterClientSuite) Test_GetRulesSplitsIDs() {
+	requests := suite.handleRulesLookup()
+
+	ids := make([]string, MaxIDsPerRequest+1)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	req, output := suite.client.GetRules(&GetRulesInput{IDs: ids})
+
+	suite.Require().Nil(req.Send())
+	suite.Assert().Equal(2, *requests)
+	suite.Assert().Equal(MaxIDsPerRequest+1, len(output.Data))
+	suite.Assert().Equal("100", output.Data[MaxIDsPerRequest].ID.String())
+}
+
+func (suite *twitterClientSuite) Test_ChunkedLookupOutputMismatch() {
+	suite.handleRulesLookup()
+
+	lookup := &ChunkedLookup{
+		IDs: []string{"1"},
+		NewRequest: func(ids []string) *Request {
+			req, _ := suite.client.GetRules(&GetRulesInput{IDs: ids})
+			return req
+		},
+	}
+	err := lookup.Send(context.Background(), &StreamTweetsOutput{})
+
+	suite.Assert().True(errors.Is(err, ErrChunkOutputMismatch))
+}
+
+func (suite *twitterClientSuite) Test_ChunkedLookupError() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Query().Get("ids"), "2") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"title": "Invalid Request", "detail": "One or more parameters to your request was invalid.", "type": "https://api.twitter.com/2/problems/invalid-request"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": [{"id": "%s", "value": "rule"}]}`, r.URL.Query().Get("ids"))
+	})
+
+	lookup := &ChunkedLookup{
+		IDs:       []string{"1", "2", "3"},
+		ChunkSize: 1,
+		NewRequest: func(ids []string) *Request {
+			req, _ := suite.client.GetRules(&GetRulesInput{IDs: ids})
+			return req
+		},
+	}
+	output := &GetRulesOutput{}
+	err := lookup.Send(context.Background(), output)
+
+	var chunkErr *ChunkError
+	suite.Require().True(errors.As(err, &chunkErr))
+	suite.Assert().Equal([]string{"2"}, chunkErr.IDs)
+	suite.Assert().Equal(2, len(output.Data))
+}
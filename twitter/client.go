@@ -1,5 +1,16 @@
 package twitter
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidEndpoint is returned by requests of a client whose configured
+// endpoint is not a host or an absolute URL.
+var ErrInvalidEndpoint = errors.New("InvalidEndpoint: endpoint must be a host or an absolute URL")
+
 // Twitter API information constants
 const (
 	EndPoint   = "https://api.twitter.com" // Base endpoint for for Twitter URL
@@ -10,6 +21,10 @@ const (
 type APIInfo struct {
 	Endpoint   string
 	APIVersion string
+
+	// The error the configured endpoint could not be parsed with, which is
+	// returned by every request of the client.
+	err error
 }
 
 // Client is twitter client to interact with Twitter API.
@@ -17,14 +32,41 @@ type Client struct {
 	Config  *Config
 	APIInfo APIInfo
 	Retryer Retryer
+
+	// StreamRetryer guides how the client's streams reconnect once they are
+	// disconnected.
+	StreamRetryer StreamRetryer
+
+	// Handlers are the request handler lists every request of the client
+	// is created with. Changes apply to requests created afterwards. The
+	// DefaultHandlers are used if every list is empty, as for a Client built
+	// as a struct literal.
+	Handlers Handlers
+
+	// StreamHandlers are the stream handler lists every stream of the
+	// client is created with. Changes apply to streams created afterwards.
+	// The DefaultStreamHandlers are used if every list is empty.
+	StreamHandlers StreamHandlers
+
+	// RateLimits keeps the last rate limit reported for each endpoint
+	// the client sent a request to.
+	RateLimits *RateLimitTracker
+
+	// RateLimiter keeps requests of the client within the quotas of their
+	// endpoints, as set by the config's client rate limit policy.
+	RateLimiter *RateLimiter
 }
 
 // NewClient returns a new Twitter API client that uses default handlers and configs.
 func NewClient(cfg *Config) *Client {
 	cfg = resolveConfig(cfg)
 	client := &Client{
-		Config:  cfg,
-		APIInfo: newAPIInfo(),
+		Config:         cfg,
+		APIInfo:        newAPIInfo(cfg),
+		Handlers:       DefaultHandlers(),
+		StreamHandlers: DefaultStreamHandlers(),
+		RateLimits:     NewRateLimitTracker(),
+		RateLimiter:    NewRateLimiter(cfg.EndpointQuotas, cfg.ClientRateLimitPolicy, cfg.Clock),
 	}
 
 	switch retryer, ok := cfg.Retryer.(Retryer); {
@@ -34,16 +76,50 @@ func NewClient(cfg *Config) *Client {
 		cfg.Logger.Warn().Msgf("%T does not implement Retryer; using DefaultRetryer instead", cfg.Retryer)
 		fallthrough
 	default:
-		cfg.Retryer = noRetryer{}
+		client.Retryer = DefaultRetryer{NumMaxRetries: cfg.MaxRetries}
+	}
+
+	client.StreamRetryer = cfg.StreamRetryer
+	if client.StreamRetryer == nil {
+		client.StreamRetryer = DefaultStreamRetryer{}
+	}
+
+	if err := client.APIInfo.err; err != nil {
+		cfg.Logger.Error().Err(err).Msg("Invalid endpoint, requests of the client will fail")
 	}
 
 	return client
 }
 
-// newAPIInfo returns twitter API basic information such as api endpoint and version
-func newAPIInfo() APIInfo {
-	return APIInfo{
+// RateLimit returns the last rate limit reported for the endpoint name, and
+// false if the client did not send a request to the endpoint yet.
+func (c *Client) RateLimit(name string) (RateLimit, bool) {
+	return c.RateLimits.Get(name)
+}
+
+// newAPIInfo returns twitter API basic information such as api endpoint and version.
+// The config's endpoint takes precedence over the default endpoint.
+func newAPIInfo(cfg *Config) APIInfo {
+	info := APIInfo{
 		Endpoint:   EndPoint,
 		APIVersion: APIVersion,
 	}
+	if cfg.Endpoint != "" {
+		info.Endpoint, info.err = parseEndpoint(cfg.Endpoint)
+	}
+	return info
+}
+
+// parseEndpoint returns the endpoint as an absolute URL without a trailing
+// slash. A bare host, such as "api.x.com", defaults to HTTPS.
+func parseEndpoint(endpoint string) (string, error) {
+	raw := endpoint
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("%w, got %q", ErrInvalidEndpoint, endpoint)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
 }
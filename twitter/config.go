@@ -3,6 +3,7 @@ package twitter
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -13,11 +14,33 @@ type RequestRetryer interface{}
 // ClientLogLevel sets log level for the client, defaults to info
 type ClientLogLevel int8
 
+// PartialErrorPolicy sets how requests handle partial errors returned
+// alongside data in successful responses.
+type PartialErrorPolicy int8
+
+const (
+	// IgnorePartialErrors leaves partial errors on the request's output only.
+	IgnorePartialErrors PartialErrorPolicy = iota
+
+	// ReturnPartialErrors makes requests return a *PartialFailure when the
+	// response carries partial errors. The output is still filled.
+	ReturnPartialErrors
+)
+
 // A Config provides client configuration for sending requests to Twitter API
 type Config struct {
 	// The credentials to use when signing requests
 	Credentials *Credentials
 
+	// The store the credentials of users are looked up in, for requests made
+	// on behalf of a user with the WithUserID option.
+	CredentialStore CredentialStore
+
+	// The base endpoint requests are sent to, for example an API gateway or
+	// a local stand-in for the Twitter API such as "http://localhost:8080".
+	// A bare host such as "api.x.com" defaults to HTTPS. Defaults to EndPoint.
+	Endpoint string
+
 	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
 	HTTPClient *http.Client
 
@@ -30,10 +53,43 @@ type Config struct {
 	// Retryer guides how HTTP requests should be retried in case of
 	// recoverable failures.
 	//
-	// When nil or the value does not implement the request.Retryer interface,
-	// the client.DefaultRetryer will be used.
+	// When nil or the value does not implement the Retryer interface,
+	// the DefaultRetryer will be used with MaxRetries as its retry limit.
 	Retryer RequestRetryer
 
+	// StreamRetryer guides how disconnected streams reconnect. Defaults to
+	// the DefaultStreamRetryer.
+	StreamRetryer StreamRetryer
+
+	// StreamStallTimeout is the time streams wait for data or a keep-alive
+	// before they reconnect. Defaults to DefaultStreamStallTimeout, and a
+	// negative timeout disables stall detection.
+	StreamStallTimeout time.Duration
+
+	// WaitOnRateLimit makes requests which are rate limited wait until the
+	// rate limit window resets before they are retried, instead of using the
+	// retryer's delay. It applies to any Retryer, but whether and how often a
+	// request is retried is still up to the retryer, so requests are not
+	// retried at all if its MaxRetries is zero.
+	WaitOnRateLimit bool
+
+	// ClientRateLimitPolicy sets whether requests are kept within the quotas
+	// of their endpoints by a client-side rate limiter, and whether they wait
+	// for budget or fail fast. Defaults to ClientRateLimitDisabled.
+	ClientRateLimitPolicy ClientRateLimitPolicy
+
+	// EndpointQuotas overrides the DefaultEndpointQuotas of the client-side
+	// rate limiter, keyed by endpoint name.
+	EndpointQuotas map[string][]Quota
+
+	// The clock the client-side rate limiter uses. Defaults to the system
+	// time.
+	Clock Clock
+
+	// PartialErrorPolicy sets whether partial errors returned alongside data
+	// are turned into a request error. Defaults to IgnorePartialErrors.
+	PartialErrorPolicy PartialErrorPolicy
+
 	// See the ClientLogMode type documentation for the complete set of logging modes and available
 	// configuration.
 	ClientLogLevel ClientLogLevel
@@ -51,6 +107,19 @@ func (c *Config) WithCredentials(creds *Credentials) *Config {
 	return c
 }
 
+// WithCredentialStore sets a config CredentialStore value returning a Config
+// pointer for chaining.
+func (c *Config) WithCredentialStore(store CredentialStore) *Config {
+	c.CredentialStore = store
+	return c
+}
+
+// WithEndpoint sets a config Endpoint value returning a Config pointer for chaining.
+func (c *Config) WithEndpoint(endpoint string) *Config {
+	c.Endpoint = endpoint
+	return c
+}
+
 // WithHTTPClient sets a config HTTPClient value returning a Config pointe for chaining.
 func (c *Config) WithHTTPClient(client *http.Client) *Config {
 	c.HTTPClient = client
@@ -83,6 +152,48 @@ func (c *Config) WithRetryer(retryer RequestRetryer) *Config {
 	return c
 }
 
+// WithStreamRetryer sets a config StreamRetryer value returning a Config pointer for chaining.
+func (c *Config) WithStreamRetryer(retryer StreamRetryer) *Config {
+	c.StreamRetryer = retryer
+	return c
+}
+
+// WithStreamStallTimeout sets a config StreamStallTimeout value returning a Config pointer for chaining.
+func (c *Config) WithStreamStallTimeout(timeout time.Duration) *Config {
+	c.StreamStallTimeout = timeout
+	return c
+}
+
+// WithWaitOnRateLimit sets a config WaitOnRateLimit value returning a Config pointer for chaining.
+func (c *Config) WithWaitOnRateLimit(wait bool) *Config {
+	c.WaitOnRateLimit = wait
+	return c
+}
+
+// WithPartialErrorPolicy sets a config PartialErrorPolicy value returning a Config pointer for chaining.
+func (c *Config) WithPartialErrorPolicy(policy PartialErrorPolicy) *Config {
+	c.PartialErrorPolicy = policy
+	return c
+}
+
+// WithClientRateLimitPolicy sets a config ClientRateLimitPolicy value returning a Config pointer for chaining.
+func (c *Config) WithClientRateLimitPolicy(policy ClientRateLimitPolicy) *Config {
+	c.ClientRateLimitPolicy = policy
+	return c
+}
+
+// WithEndpointQuotas sets a config EndpointQuotas value returning a Config pointer for chaining.
+func (c *Config) WithEndpointQuotas(quotas map[string][]Quota) *Config {
+	c.EndpointQuotas = quotas
+	return c
+}
+
+// WithClock sets a config Clock value returning a Config pointer for chaining.
+func (c *Config) WithClock(clock Clock) *Config {
+	c.Clock = clock
+	return c
+}
+
 // NewDefaultLogger returns a Logger which will write log messages to stdout.
 func newDefaultLogger() zerolog.Logger {
 	return zerolog.New(os.Stderr).With().Timestamp().Logger()
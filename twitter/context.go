@@ -0,0 +1,27 @@
+package twitter
+
+import (
+	"context"
+	"time"
+)
+
+// backgroundCtx is the context used when a request or stream was not given
+// one.
+var backgroundCtx = context.Background()
+
+// sleepWithContext will wait for the timer duration to expire, or the context
+// is canceled. Whichever happens first. If the context is canceled the
+// Context's error will be returned.
+func sleepWithContext(ctx context.Context, dur time.Duration) error {
+	t := time.NewTimer(dur)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		break
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
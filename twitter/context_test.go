@@ -0,0 +1,69 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_SendWithContextAbortsRetryDelay() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	req.Retryer = DefaultRetryer{NumMaxRetries: 3, MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour}
+
+	start := time.Now()
+	err := req.SendWithContext(ctx)
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(1, attempts)
+	suite.Assert().True(time.Since(start) < time.Second)
+}
+
+func (suite *twitterClientSuite) Test_SendWithContextCanceled() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	req.Retryer = newTestRetryer(3)
+	err := req.SendWithContext(ctx)
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(0, req.RetryCount)
+}
+
+func (suite *twitterClientSuite) Test_StreamTweetsWithContext() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		// Keep the connection open until the client goes away.
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := suite.client.StreamTweetsWithContext(ctx, StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	message := <-stream.MessageQueue
+	suite.Assert().NotNil(message)
+
+	cancel()
+	select {
+	case _, ok := <-stream.MessageQueue:
+		suite.Assert().False(ok)
+	case <-time.After(time.Second):
+		suite.Fail("stream did not stop after its context was canceled")
+	}
+}
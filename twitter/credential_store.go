@@ -0,0 +1,46 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrCredentialStoreNotSet is emitted when a request acts on behalf of a
+	// user, but no credential store is configured to look the user up.
+	ErrCredentialStoreNotSet = errors.New("CredentialStoreNotSet: a credential store is required to sign requests on behalf of a user ID")
+)
+
+// A CredentialStore looks up the credentials of the users a client acts on
+// behalf of. Requests and streams made with the WithUserID option are signed
+// with the credentials the store returns for the user, which are looked up
+// before every attempt so they can be refreshed or rotated by the store.
+//
+// A CredentialStore must be safe to use concurrently.
+type CredentialStore interface {
+	// Credentials returns the credentials of the user, or an error if there
+	// are none.
+	Credentials(ctx context.Context, userID string) (*Credentials, error)
+}
+
+// The CredentialStoreFunc type is an adapter to allow the use of an ordinary
+// function as a CredentialStore.
+type CredentialStoreFunc func(ctx context.Context, userID string) (*Credentials, error)
+
+// Credentials calls f(ctx, userID).
+func (f CredentialStoreFunc) Credentials(ctx context.Context, userID string) (*Credentials, error) {
+	return f(ctx, userID)
+}
+
+// resolveCredentials returns the credentials to sign with. The credentials of
+// the user are looked up in the configured credential store if a user ID is
+// set, and the config's credentials are used otherwise.
+func resolveCredentials(ctx context.Context, cfg Config, userID string) (*Credentials, error) {
+	if userID == "" {
+		return cfg.Credentials, nil
+	}
+	if cfg.CredentialStore == nil {
+		return nil, ErrCredentialStoreNotSet
+	}
+	return cfg.CredentialStore.Credentials(ctx, userID)
+}
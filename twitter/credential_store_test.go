@@ -0,0 +1,107 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_WithRequestCredentials() {
+	var authorization []string
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{}, WithRequestCredentials(NewCredentials(Value{BearerToken: "TENANT"})))
+	suite.Require().Nil(req.Send())
+	req, _ = suite.client.GetRules(&GetRulesInput{})
+	suite.Require().Nil(req.Send())
+
+	suite.Assert().Equal([]string{"Bearer TENANT", "Bearer TEST"}, authorization)
+}
+
+func (suite *twitterClientSuite) Test_WithUserIDLooksUpCredentialStore() {
+	var m sync.Mutex
+	lookups := map[string]int{}
+	users := map[string]*Credentials{
+		"2244994945": NewOAuth2Credentials(suite.newOAuth2Config(), &OAuth2Token{
+			AccessToken:  "revoked",
+			RefreshToken: "refresh",
+			Expiry:       time.Now().Add(time.Hour),
+		}),
+		"783214": NewCredentials(Value{OAuth2Token: &OAuth2Token{AccessToken: "783214-token"}}),
+	}
+	suite.client.Config.WithCredentialStore(CredentialStoreFunc(func(ctx context.Context, userID string) (*Credentials, error) {
+		m.Lock()
+		defer m.Unlock()
+		lookups[userID]++
+		creds, ok := users[userID]
+		if !ok {
+			return nil, fmt.Errorf("no credentials for user %s", userID)
+		}
+		return creds, nil
+	}))
+
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "refresh_token": "rotated"}`)
+	})
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer fresh", "Bearer 783214-token":
+			fmt.Fprintf(w, `{"data": {}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"title": "Unauthorized", "type": "about:blank", "status": 401, "detail": "Unauthorized"}`)
+		}
+	})
+
+	req := suite.newUserContextRequest()
+	req.ApplyOptions(WithUserID("2244994945"))
+	suite.Assert().Nil(req.Send())
+
+	req = suite.newUserContextRequest()
+	req.ApplyOptions(WithUserID("783214"))
+	suite.Assert().Nil(req.Send())
+
+	req = suite.newUserContextRequest()
+	req.ApplyOptions(WithUserID("12"))
+	suite.Assert().EqualError(req.Send(), "no credentials for user 12")
+
+	// The revoked token is refreshed once the API rejects it, and the user is
+	// looked up again for the retry.
+	suite.Assert().Equal(map[string]int{"2244994945": 2, "783214": 1, "12": 1}, lookups)
+	value, _ := users["2244994945"].Retrieve()
+	suite.Assert().Equal("fresh", value.OAuth2Token.AccessToken)
+}
+
+func (suite *twitterClientSuite) Test_WithUserIDRequiresCredentialStore() {
+	req, _ := suite.client.GetRules(&GetRulesInput{}, WithUserID("2244994945"))
+
+	suite.Assert().True(errors.Is(req.Send(), ErrCredentialStoreNotSet))
+}
+
+func (suite *twitterClientSuite) Test_WithStreamCredentials() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().Equal("Bearer TENANT", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := suite.client.StreamTweetsWithContext(ctx, StreamTweetsInput{},
+		WithStreamCredentials(NewCredentials(Value{BearerToken: "TENANT"})), WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	select {
+	case message := <-stream.MessageQueue:
+		suite.Assert().NotNil(message)
+	case <-time.After(time.Second):
+		suite.Fail("stream did not receive a message")
+	}
+}
@@ -1,22 +1,117 @@
 package twitter
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"sync"
 )
 
 var (
 	// ErrCredentialsEmpty is emitted when credentials are empty.
 	ErrCredentialsEmpty = errors.New("EmptyCredentials: Credentials value is empty")
+
+	// ErrOAuth1CredentialsEmpty is emitted when OAuth 1.0a user context
+	// credentials are required but not set.
+	ErrOAuth1CredentialsEmpty = errors.New("EmptyOAuth1Credentials: consumer key, consumer secret, access token and access secret are required")
+
+	// ErrInvalidateNotSupported is emitted when invalidating credentials
+	// whose provider can not invalidate its token.
+	ErrInvalidateNotSupported = errors.New("InvalidateNotSupported: credentials provider can not invalidate its token")
 )
 
 // Value is the Twitter credentials values.
 type Value struct {
+	// The app-only bearer token.
 	BearerToken string
+
+	// The OAuth 1.0a consumer key and secret of the app.
+	ConsumerKey    string
+	ConsumerSecret string
+
+	// The OAuth 1.0a access token and secret of the user the app acts on
+	// behalf of.
+	AccessToken  string
+	AccessSecret string
+
+	// The OAuth 2.0 user context access token, along with its expiry and
+	// refresh token.
+	OAuth2Token *OAuth2Token
+
+	// The name of the provider the credentials were retrieved from, if any.
+	ProviderName string
 }
 
-// A Credentials is a set of credentials which are set programmatically,
+// HasOAuth1 checks if the OAuth 1.0a user context credentials are set.
+func (v Value) HasOAuth1() bool {
+	return v.ConsumerKey != "" && v.ConsumerSecret != "" && v.AccessToken != "" && v.AccessSecret != ""
+}
+
+// isEmpty checks if none of the bearer token, OAuth 2.0 access token or
+// OAuth 1.0a user context credentials are set.
+func (v Value) isEmpty() bool {
+	return v.BearerToken == "" && v.OAuth2Token == nil && !v.HasOAuth1()
+}
+
+// sameTokens checks if the values hold the same bearer token, OAuth 2.0
+// access token and OAuth 1.0a access token.
+func (v Value) sameTokens(o Value) bool {
+	var accessToken, otherAccessToken string
+	if v.OAuth2Token != nil {
+		accessToken = v.OAuth2Token.AccessToken
+	}
+	if o.OAuth2Token != nil {
+		otherAccessToken = o.OAuth2Token.AccessToken
+	}
+	return v.BearerToken == o.BearerToken && accessToken == otherAccessToken && v.AccessToken == o.AccessToken
+}
+
+// A Provider is the interface for any component which will provide credentials
+// Value. A provider is required to manage its own expired state, and what it
+// means to be expired.
+type Provider interface {
+	// Retrieve returns nil if it successfully retrieved the value.
+	// Error is returned if the value were not obtainable, or empty.
+	Retrieve() (Value, error)
+
+	// IsExpired returns if the credentials are no longer valid, and need
+	// to be retrieved.
+	IsExpired() bool
+}
+
+// A ProviderWithContext is a Provider that can retrieve credentials with a
+// Context, for providers which call out to the network.
+type ProviderWithContext interface {
+	Provider
+
+	RetrieveWithContext(context.Context) (Value, error)
+}
+
+// An Invalidator is a Provider which can revoke the bearer token it
+// retrieved, such as the AppOnlyProvider.
+type Invalidator interface {
+	Provider
+
+	Invalidate(ctx context.Context, bearerToken string) error
+}
+
+// A Credentials is a set of credentials which are set programmatically, or
+// retrieved from a Provider and cached until the provider reports them as
+// expired. OAuth 2.0 access tokens are refreshed when they are about to expire.
+//
+// Credentials is safe to use concurrently.
 type Credentials struct {
 	Value
+
+	provider     Provider
+	oauth2Config *OAuth2Config
+	tokenStore   TokenStore
+	tokenKey     string
+	unstored     bool
+	storeErr     error
+	forceRefresh bool
+	m            sync.Mutex
 }
 
 // NewCredentials returns a pointer to a new Credentials with the provider set.
@@ -27,18 +122,212 @@ func NewCredentials(creds Value) *Credentials {
 	return c
 }
 
+// NewCredentialsFromProvider returns a pointer to a new Credentials which
+// retrieves its value from the provider, and caches it until the provider
+// reports it as expired or the credentials are expired with Expire.
+func NewCredentialsFromProvider(provider Provider) *Credentials {
+	return &Credentials{
+		provider:     provider,
+		forceRefresh: true,
+	}
+}
+
+// NewOAuth2Credentials returns a pointer to a new Credentials holding the
+// OAuth 2.0 user context token. The token is refreshed with the app's config
+// when it is about to expire, provided a refresh token was granted.
+func NewOAuth2Credentials(cfg *OAuth2Config, token *OAuth2Token) *Credentials {
+	return &Credentials{
+		Value:        Value{OAuth2Token: token},
+		oauth2Config: cfg,
+	}
+}
+
+// NewStoredOAuth2Credentials returns a pointer to a new Credentials holding
+// the OAuth 2.0 user context token stored in the token store under the account
+// key. The token is loaded from the store when first retrieved, and stored
+// again after every refresh so rotated refresh tokens survive restarts.
+func NewStoredOAuth2Credentials(cfg *OAuth2Config, store TokenStore, key string) *Credentials {
+	return &Credentials{
+		oauth2Config: cfg,
+		tokenStore:   store,
+		tokenKey:     key,
+	}
+}
+
 // Retrieve returns the credentials or error if the credentials are invalid.
 func (c *Credentials) Retrieve() (Value, error) {
-	if c.BearerToken == "" {
+	return c.RetrieveWithContext(backgroundCtx)
+}
+
+// RetrieveWithContext returns the credentials or error if the credentials are
+// invalid. Credentials of a provider are retrieved again once expired, and an
+// OAuth 2.0 access token which is about to expire, or was marked expired, is
+// refreshed first and written back to the token store, if any. A failure to
+// store the refreshed token does not fail the call; it is reported by
+// StoreError, and storing is retried with every retrieval until it succeeds.
+func (c *Credentials) RetrieveWithContext(ctx context.Context) (Value, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.provider != nil && (c.forceRefresh || c.provider.IsExpired()) {
+		value, err := retrieveFromProvider(ctx, c.provider)
+		if err != nil {
+			return Value{}, err
+		}
+		c.Value = value
+		c.forceRefresh = false
+	}
+
+	if c.tokenStore != nil && c.OAuth2Token == nil {
+		token, err := c.tokenStore.Get(ctx, c.tokenKey)
+		if err != nil {
+			return Value{}, err
+		}
+		c.OAuth2Token = token
+	}
+
+	if c.refreshable() && (c.forceRefresh || c.OAuth2Token.expiresWithin(oauth2ExpiryDelta)) {
+		token, err := c.oauth2Config.Refresh(ctx, c.OAuth2Token.RefreshToken)
+		if err != nil {
+			return Value{}, err
+		}
+		c.OAuth2Token = token
+		c.forceRefresh = false
+		c.unstored = c.tokenStore != nil
+	}
+
+	// The refreshed token is kept even if storing it fails, so the next
+	// retrieval does not refresh with a rotated refresh token.
+	if c.unstored {
+		c.storeErr = c.tokenStore.Put(ctx, c.tokenKey, c.OAuth2Token)
+		c.unstored = c.storeErr != nil
+	}
+
+	if !c.isSet() {
 		return Value{}, ErrCredentialsEmpty
 	}
 	return c.Value, nil
 }
 
+// expireValue expires the credentials like Expire, but only if they still
+// hold the tokens of the value, so credentials which were refreshed since the
+// value was retrieved are not refreshed again.
+func (c *Credentials) expireValue(v Value) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.Value.sameTokens(v) {
+		c.forceRefresh = true
+	}
+}
+
+// StoreError returns the error the last refreshed OAuth 2.0 token failed to
+// be stored with, or nil once it was stored.
+func (c *Credentials) StoreError() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.storeErr
+}
+
+// Expire marks the credentials as expired, so they are retrieved from the
+// provider again or the OAuth 2.0 access token is refreshed the next time
+// the credentials are retrieved.
+func (c *Credentials) Expire() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.forceRefresh = true
+}
+
+// Invalidate revokes the bearer token the credentials hold with their
+// provider, and expires the credentials so a new token is retrieved the next
+// time. Returns ErrInvalidateNotSupported if the provider is not an
+// Invalidator.
+func (c *Credentials) Invalidate(ctx context.Context) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	p, ok := c.provider.(Invalidator)
+	if !ok {
+		return ErrInvalidateNotSupported
+	}
+	if c.BearerToken == "" {
+		return ErrCredentialsEmpty
+	}
+	if err := p.Invalidate(ctx, c.BearerToken); err != nil {
+		return err
+	}
+	c.BearerToken = ""
+	c.forceRefresh = true
+	return nil
+}
+
 // IsSet checks if the token is set on the credential struct
 func (c *Credentials) IsSet() bool {
-	if c.BearerToken == "" {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.isSet()
+}
+
+func (c *Credentials) isSet() bool {
+	return !c.Value.isEmpty()
+}
+
+// canRefresh checks if the credentials are retrieved from a provider or hold
+// an OAuth 2.0 access token which can be refreshed.
+func (c *Credentials) canRefresh() bool {
+	if c == nil {
 		return false
 	}
-	return true
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.provider != nil || c.refreshable()
+}
+
+func (c *Credentials) refreshable() bool {
+	return c.oauth2Config != nil && c.OAuth2Token != nil && c.OAuth2Token.RefreshToken != ""
+}
+
+// A ProviderError is returned when a provider fails to retrieve credentials.
+type ProviderError struct {
+	// The name of the provider.
+	ProviderName string
+
+	// The description of the failure.
+	Description string
+
+	// The underlying error, if any.
+	Err error
+}
+
+func newProviderError(providerName, description string, err error) *ProviderError {
+	return &ProviderError{ProviderName: providerName, Description: description, Err: err}
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *ProviderError) Error() string {
+	return SprintError(fmt.Sprintf("%s: %s", e.ProviderName, e.Description), "", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+func retrieveFromProvider(ctx context.Context, provider Provider) (Value, error) {
+	if p, ok := provider.(ProviderWithContext); ok {
+		return p.RetrieveWithContext(ctx)
+	}
+	return provider.Retrieve()
+}
+
+// signHTTPRequest sets the Authorization header of the HTTP request. The
+// first auth type the endpoint supports which the credentials hold is used.
+// Endpoints without auth types use the bearer token if set, then the OAuth 2.0
+// user context access token, and OAuth 1.0a user context signing otherwise.
+func signHTTPRequest(req *http.Request, endpoint *EndPointInfo, value Value) error {
+	authType, err := authorize(endpoint, value)
+	if err != nil {
+		return err
+	}
+	return signHTTPRequestWithAuth(req, value, authType)
 }
@@ -0,0 +1,87 @@
+package twitter
+
+import (
+	"errors"
+	"os"
+)
+
+// EnvProviderName provides a name of the Env provider
+const EnvProviderName = "EnvProvider"
+
+var (
+	// ErrEnvCredentialsNotFound is returned when none of the credentials
+	// environment variables are set.
+	ErrEnvCredentialsNotFound = errors.New("EnvCredentialsNotFound: TWITTER_BEARER_TOKEN or the OAuth 1.0a credentials not found in environment")
+)
+
+// Environment variables the EnvProvider reads credentials from.
+const (
+	EnvBearerToken    = "TWITTER_BEARER_TOKEN"
+	EnvConsumerKey    = "TWITTER_CONSUMER_KEY"
+	EnvConsumerSecret = "TWITTER_CONSUMER_SECRET"
+	EnvAccessToken    = "TWITTER_ACCESS_TOKEN"
+	EnvAccessSecret   = "TWITTER_ACCESS_SECRET"
+)
+
+// A EnvProvider retrieves credentials from the environment variables of the
+// running process. The credentials expire once any of the environment
+// variables changes, so rotated credentials are used for the next request.
+//
+// Environment variables used:
+//
+// * App-only bearer token: TWITTER_BEARER_TOKEN
+//
+// * OAuth 1.0a consumer key and secret: TWITTER_CONSUMER_KEY and
+// TWITTER_CONSUMER_SECRET
+//
+// * OAuth 1.0a access token and secret: TWITTER_ACCESS_TOKEN and
+// TWITTER_ACCESS_SECRET
+type EnvProvider struct {
+	retrieved bool
+	env       [5]string
+}
+
+// NewEnvCredentials returns a pointer to a new Credentials object
+// wrapping the environment variable provider.
+func NewEnvCredentials() *Credentials {
+	return NewCredentialsFromProvider(&EnvProvider{})
+}
+
+// Retrieve retrieves the keys from the environment.
+func (e *EnvProvider) Retrieve() (Value, error) {
+	e.retrieved = false
+	e.env = readCredentialsEnv()
+
+	v := Value{
+		BearerToken:    e.env[0],
+		ConsumerKey:    e.env[1],
+		ConsumerSecret: e.env[2],
+		AccessToken:    e.env[3],
+		AccessSecret:   e.env[4],
+		ProviderName:   EnvProviderName,
+	}
+	if v.isEmpty() {
+		return Value{ProviderName: EnvProviderName}, ErrEnvCredentialsNotFound
+	}
+
+	e.retrieved = true
+	return v, nil
+}
+
+// IsExpired returns if the credentials have not been retrieved, or the
+// environment variables changed since.
+func (e *EnvProvider) IsExpired() bool {
+	return !e.retrieved || e.env != readCredentialsEnv()
+}
+
+// readCredentialsEnv returns the values of the credentials environment
+// variables.
+func readCredentialsEnv() [5]string {
+	return [5]string{
+		os.Getenv(EnvBearerToken),
+		os.Getenv(EnvConsumerKey),
+		os.Getenv(EnvConsumerSecret),
+		os.Getenv(EnvAccessToken),
+		os.Getenv(EnvAccessSecret),
+	}
+}
@@ -0,0 +1,74 @@
+package twitter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	for k, v := range env {
+		prev, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		k := k
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(k, prev)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+}
+
+func TestEnvProviderRetrieve(t *testing.T) {
+	setEnv(t, map[string]string{
+		EnvBearerToken:    "bearer",
+		EnvConsumerKey:    "consumer key",
+		EnvConsumerSecret: "consumer secret",
+		EnvAccessToken:    "access token",
+		EnvAccessSecret:   "access secret",
+	})
+
+	p := &EnvProvider{}
+	assert.True(t, p.IsExpired())
+
+	value, err := p.Retrieve()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "bearer", value.BearerToken)
+	assert.True(t, value.HasOAuth1())
+	assert.Equal(t, EnvProviderName, value.ProviderName)
+	assert.False(t, p.IsExpired())
+}
+
+func TestEnvProviderNotFound(t *testing.T) {
+	setEnv(t, map[string]string{
+		EnvBearerToken: "",
+		EnvConsumerKey: "consumer key only",
+	})
+
+	p := &EnvProvider{}
+	_, err := p.Retrieve()
+
+	assert.Equal(t, ErrEnvCredentialsNotFound, err)
+	assert.True(t, p.IsExpired())
+}
+
+func TestEnvProviderExpiresOnChange(t *testing.T) {
+	setEnv(t, map[string]string{EnvBearerToken: "bearer"})
+
+	p := &EnvProvider{}
+	_, err := p.Retrieve()
+	assert.Nil(t, err)
+	assert.False(t, p.IsExpired())
+
+	setEnv(t, map[string]string{EnvBearerToken: "rotated"})
+	assert.True(t, p.IsExpired())
+
+	value, err := p.Retrieve()
+	assert.Nil(t, err)
+	assert.Equal(t, "rotated", value.BearerToken)
+	assert.False(t, p.IsExpired())
+}
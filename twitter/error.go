@@ -1,7 +1,12 @@
 package twitter
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // A RequestFailure is an interface to extract request failure information from
@@ -19,18 +24,21 @@ type RequestFailure interface {
 // A Diagnostic wraps basic extra information Twitter API returns
 // once a non 200 status code is returned.
 type Diagnostic struct {
-	ClientID              string `json:"client_id"`
-	RequirementEnrollment string `json:"required_enrollment"`
-	RegistrationURL       string `json:"registration_url"`
-	Title                 string `json:"title"`
-	Detail                string `json:"detail"`
-	Reason                string `json:"reason"`
-	Type                  string `json:"type"`
+	ClientID              string         `json:"client_id"`
+	RequirementEnrollment string         `json:"required_enrollment"`
+	RegistrationURL       string         `json:"registration_url"`
+	Title                 string         `json:"title"`
+	Detail                string         `json:"detail"`
+	Reason                string         `json:"reason"`
+	Type                  string         `json:"type"`
+	Status                int            `json:"status"`
+	ConnectionIssue       string         `json:"connection_issue"`
+	Errors                []PartialError `json:"errors"`
 }
 
 // IsFiled checks if error information is in Twitter API response body
 func (d Diagnostic) IsFiled() bool {
-	if d.Detail != "" || d.Type != "" || d.Title != "" {
+	if d.Detail != "" || d.Type != "" || d.Title != "" || len(d.Errors) > 0 {
 		return true
 	}
 	return false
@@ -52,9 +60,155 @@ func (d Diagnostic) String() string {
 	if d.Reason != "" {
 		defaultMessage += " " + fmt.Sprintf("Reason: %s", d.Reason)
 	}
+	if d.ConnectionIssue != "" {
+		defaultMessage += " " + fmt.Sprintf("Connection issue: %s", d.ConnectionIssue)
+	}
+	for _, err := range d.Errors {
+		defaultMessage += " " + fmt.Sprintf("Error: %s", err.Error())
+	}
 	return defaultMessage
 }
 
+// A PartialError describes a problem the Twitter API reported for a part of
+// a request, for example a deleted Tweet, a suspended user or a rejected rule.
+// Partial errors are returned in the `errors` array of responses which may
+// still carry data.
+type PartialError struct {
+	ResourceType string   `json:"resource_type,omitempty"`
+	ResourceID   string   `json:"resource_id,omitempty"`
+	Parameter    string   `json:"parameter,omitempty"`
+	Section      string   `json:"section,omitempty"`
+	Value        string   `json:"value,omitempty"`
+	ID           string   `json:"id,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Detail       string   `json:"detail,omitempty"`
+	Details      []string `json:"details,omitempty"`
+	Type         string   `json:"type,omitempty"`
+}
+
+// Error returns the string representation of the partial error.
+// Satisfies the error interface.
+func (e PartialError) Error() string {
+	message := e.Title
+	if e.Detail != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Detail)
+	}
+	if len(e.Details) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(e.Details, ", "))
+	}
+	if e.ResourceType != "" {
+		message = fmt.Sprintf("%s (resource type: %s, %s: %s)", message, e.ResourceType, e.Parameter, e.Value)
+	}
+	return message
+}
+
+// A PartialFailure is returned by requests which succeeded with partial
+// errors when the client is configured with ReturnPartialErrors. The
+// request's output is still filled with the data that was returned.
+type PartialFailure struct {
+	// The name of the endpoint the request was sent to.
+	EndPointName string
+
+	// The partial errors returned in the response body.
+	Errors []PartialError
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *PartialFailure) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d partial errors returned by: %s: %s", len(e.Errors), e.EndPointName, strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the partial errors matches the target, which is a
+// PartialError or *PartialError. Zero value fields of the target are ignored,
+// so
+//
+//	errors.Is(err, &PartialError{ResourceType: "tweet"})
+//
+// is true if any Tweet could not be returned.
+func (e *PartialFailure) Is(target error) bool {
+	var t PartialError
+	switch v := target.(type) {
+	case PartialError:
+		t = v
+	case *PartialError:
+		if v == nil {
+			return false
+		}
+		t = *v
+	default:
+		return false
+	}
+	for _, err := range e.Errors {
+		if err.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets the target to the first partial error, if the target is a
+// *PartialError or **PartialError. The other partial errors are reached
+// through Errors.
+func (e *PartialFailure) As(target interface{}) bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+	switch t := target.(type) {
+	case *PartialError:
+		*t = e.Errors[0]
+	case **PartialError:
+		err := e.Errors[0]
+		*t = &err
+	default:
+		return false
+	}
+	return true
+}
+
+// matches reports whether the partial error matches the non-zero fields of
+// the target.
+func (e PartialError) matches(t PartialError) bool {
+	return (t.ResourceType == "" || t.ResourceType == e.ResourceType) &&
+		(t.ResourceID == "" || t.ResourceID == e.ResourceID) &&
+		(t.Parameter == "" || t.Parameter == e.Parameter) &&
+		(t.Value == "" || t.Value == e.Value) &&
+		(t.Title == "" || t.Title == e.Title) &&
+		(t.Type == "" || t.Type == e.Type)
+}
+
+// partialErrorsOutput is implemented by output types which model the partial
+// errors of a response.
+type partialErrorsOutput interface {
+	PartialErrors() []PartialError
+}
+
+// PartialErrorHandler is a request handler which sets a *PartialFailure on
+// the request if the request's output carries partial errors and the client
+// is configured to return them.
+var PartialErrorHandler = HandlerFunction{
+	Name: "PartialErrorHandler",
+	Fn: func(r *Request) {
+		if r.Config.PartialErrorPolicy != ReturnPartialErrors {
+			return
+		}
+		output, ok := r.Data.(partialErrorsOutput)
+		if !ok {
+			return
+		}
+		if errs := output.PartialErrors(); len(errs) > 0 {
+			r.Error = &PartialFailure{
+				EndPointName: r.EndPointInfo.Name,
+				Errors:       errs,
+			}
+		}
+	},
+}
+
 // NewRequestFailure returns a wrapped error with additional information for
 // request status code.
 func NewRequestFailure(err error, statusCode int, message string) RequestFailure {
@@ -104,6 +258,147 @@ func (r requestError) OrigErr() error {
 	return r.twitterError
 }
 
+// Unwrap returns the wrapped error so it can be inspected with errors.Is
+// and errors.As.
+func (r requestError) Unwrap() error {
+	return r.twitterError
+}
+
+// An APIError is returned when the Twitter API responds to a request with an
+// error status code. It satisfies the RequestFailure interface and can be
+// extracted from a request's error with errors.As.
+type APIError struct {
+	// The problem details returned in the HTTP response body.
+	Diagnostic Diagnostic
+
+	// The status code of the HTTP response.
+	HTTPStatusCode int
+
+	// The name of the endpoint the request was sent to.
+	EndPointName string
+
+	// The headers of the HTTP response.
+	Header http.Header
+
+	// The error encountered while decoding the HTTP response body, if any.
+	Err error
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *APIError) Error() string {
+	extra := fmt.Sprintf("status code: %d", e.HTTPStatusCode)
+	return SprintError(e.Message(), extra, e.Err)
+}
+
+// StatusCode returns the status code of the HTTP response.
+func (e *APIError) StatusCode() int {
+	return e.HTTPStatusCode
+}
+
+// Message returns a message describing the problem the Twitter API reported.
+func (e *APIError) Message() string {
+	if e.Diagnostic.IsFiled() {
+		return fmt.Sprintf("An error ocurred in sending a request to: %s with error details: %v", e.EndPointName, e.Diagnostic.String())
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("Failed to decode JSON response of: %s to detect errors", e.EndPointName)
+	}
+	return fmt.Sprintf("An error occurred in sending a request to: %s with status: %s", e.EndPointName, http.StatusText(e.HTTPStatusCode))
+}
+
+// OrigErr returns the error encountered while decoding the HTTP response body.
+func (e *APIError) OrigErr() error {
+	return e.Err
+}
+
+// Unwrap returns the error encountered while decoding the HTTP response body.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is an *APIError matching this error. Zero
+// value fields of the target are ignored, so
+//
+//	errors.Is(err, &APIError{HTTPStatusCode: http.StatusForbidden})
+//
+// is true for any forbidden error.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return (t.HTTPStatusCode == 0 || t.HTTPStatusCode == e.HTTPStatusCode) &&
+		(t.EndPointName == "" || t.EndPointName == e.EndPointName) &&
+		(t.Diagnostic.Type == "" || t.Diagnostic.Type == e.Diagnostic.Type) &&
+		(t.Diagnostic.Title == "" || t.Diagnostic.Title == e.Diagnostic.Title)
+}
+
+// IsRateLimited returns true if the error was caused by the Twitter API rate
+// limiting the request, or by the client-side rate limiter failing it fast.
+func IsRateLimited(err error) bool {
+	var limitErr *ClientRateLimitError
+	return isErrorThrottle(err) || errors.As(err, &limitErr)
+}
+
+// IsUnauthorized returns true if the Twitter API rejected the request's
+// credentials.
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if the request's credentials are not allowed to
+// access the endpoint.
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+// IsTooManyConnections returns true if a stream could not connect because
+// the app reached the maximum number of connections allowed to the stream.
+func IsTooManyConnections(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Diagnostic.ConnectionIssue == "TooManyConnections"
+}
+
+// IsRetryable returns true if the error is a server, network or rate limit
+// failure which is worth retrying.
+func IsRetryable(err error) bool {
+	return isErrorRetryable(err) || IsRateLimited(err)
+}
+
+// RetryAfter returns how long to wait before retrying a request that failed
+// with the error, based on the response's Retry-After or rate limit reset
+// headers, or the client-side rate limiter. False is returned if the error does
+// not carry that information.
+func RetryAfter(err error) (time.Duration, bool) {
+	var limitErr *ClientRateLimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.RetryAfter, true
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(apiErr.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if limit := parseRateLimit(apiErr.Header); limit != nil {
+		delay := limit.UntilReset()
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var rf RequestFailure
+	return errors.As(err, &rf) && rf.StatusCode() == statusCode
+}
+
 // SprintError returns a string of the formatted error code.
 //
 // Both extra and origErr are optional.  If they are included their lines
@@ -0,0 +1,114 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *twitterClientSuite) Test_APIError() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"title": "Too Many Requests", "detail": "Too Many Requests", "type": "about:blank", "status": 429}`)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	var apiErr *APIError
+	suite.Require().True(errors.As(err, &apiErr))
+	suite.Assert().Equal(http.StatusTooManyRequests, apiErr.StatusCode())
+	suite.Assert().Equal(getRules, apiErr.EndPointName)
+	suite.Assert().Equal("Too Many Requests", apiErr.Diagnostic.Title)
+	suite.Assert().Equal(429, apiErr.Diagnostic.Status)
+	suite.Assert().Equal("30", apiErr.Header.Get("Retry-After"))
+
+	suite.Assert().True(IsRateLimited(err))
+	suite.Assert().True(IsRetryable(err))
+	suite.Assert().False(IsUnauthorized(err))
+	suite.Assert().True(errors.Is(err, &APIError{HTTPStatusCode: http.StatusTooManyRequests}))
+	suite.Assert().False(errors.Is(err, &APIError{HTTPStatusCode: http.StatusForbidden}))
+
+	delay, ok := RetryAfter(err)
+	suite.Assert().True(ok)
+	suite.Assert().Equal(30*time.Second, delay)
+}
+
+func (suite *twitterClientSuite) Test_APIErrorWithoutBody() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	var apiErr *APIError
+	suite.Require().True(errors.As(err, &apiErr))
+	suite.Assert().True(IsUnauthorized(err))
+	suite.Assert().False(IsRetryable(err))
+	suite.Assert().False(apiErr.Diagnostic.IsFiled())
+
+	_, ok := RetryAfter(err)
+	suite.Assert().False(ok)
+}
+
+func TestRequestErrorUnwrap(t *testing.T) {
+	origErr := errors.New("connection reset by peer")
+	err := NewRequestFailure(origErr, 0, "send request failed")
+
+	assert.True(t, errors.Is(err, origErr))
+}
+
+func (suite *twitterClientSuite) Test_PartialErrors() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": [{"id": "1165037377523306497", "value": "dog has:images"}], "errors": [{"value": "1165037377523306498", "detail": "Could not find rule with id: [1165037377523306498].", "title": "Not Found Error", "resource_type": "rule", "parameter": "ids", "type": "https://api.twitter.com/2/problems/resource-not-found"}]}`)
+	})
+
+	req, out := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal(1, len(out.Data))
+	suite.Require().Equal(1, len(out.Errors))
+	suite.Assert().Equal("rule", out.Errors[0].ResourceType)
+	suite.Assert().Equal("ids", out.Errors[0].Parameter)
+	suite.Assert().Equal("1165037377523306498", out.Errors[0].Value)
+
+	suite.client.Config.WithPartialErrorPolicy(ReturnPartialErrors)
+	req, out = suite.client.GetRules(&GetRulesInput{})
+	err = req.Send()
+
+	var partialFailure *PartialFailure
+	suite.Require().True(errors.As(err, &partialFailure))
+	suite.Assert().Equal(getRules, partialFailure.EndPointName)
+	suite.Assert().Equal(out.Errors, partialFailure.Errors)
+	suite.Assert().Equal(1, len(out.Data))
+	suite.Assert().False(IsRetryable(err))
+}
+
+func TestPartialFailureErrorsIsAs(t *testing.T) {
+	err := fmt.Errorf("lookup failed: %w", &PartialFailure{
+		EndPointName: getRules,
+		Errors: []PartialError{
+			{ResourceType: "rule", Parameter: "ids", Value: "1", Title: "Not Found Error"},
+			{ResourceType: "user", Parameter: "ids", Value: "2", Title: "Forbidden"},
+		},
+	})
+
+	var partialErr *PartialError
+	assert.True(t, errors.As(err, &partialErr))
+	assert.Equal(t, "1", partialErr.Value)
+
+	var partialErrValue PartialError
+	assert.True(t, errors.As(err, &partialErrValue))
+	assert.Equal(t, "Not Found Error", partialErrValue.Title)
+
+	assert.True(t, errors.Is(err, &PartialError{ResourceType: "user", Title: "Forbidden"}))
+	assert.True(t, errors.Is(err, PartialError{Value: "1"}))
+	assert.False(t, errors.Is(err, &PartialError{ResourceType: "tweet"}))
+}
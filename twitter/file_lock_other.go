@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package twitter
+
+import "os"
+
+// lockFile is a no-op on platforms without advisory file locks. Writes from
+// concurrent processes are not merged there.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without advisory file locks.
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+package twitter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileProviderName provides a name of the file provider
+const FileProviderName = "FileProvider"
+
+// Environment variables the FileProvider reads its defaults from.
+const (
+	EnvCredentialsFile = "TWITTER_CREDENTIALS_FILE"
+	EnvProfile         = "TWITTER_PROFILE"
+)
+
+// DefaultProfile is the profile used when none is set.
+const DefaultProfile = "default"
+
+// A FileProvider retrieves credentials from a profile of a credentials file.
+// The file holds named profiles, written either as YAML or as JSON:
+//
+//	default:
+//	  bearer_token: AAAA...
+//	bot:
+//	  consumer_key: xvz1evFS4wEEPTGEFPHBog
+//	  consumer_secret: kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw
+//	  access_token: 370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb
+//	  access_secret: LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE
+//
+// The credentials expire once the file's modification time or size changes,
+// so rotated credentials are used for the next request.
+type FileProvider struct {
+	// Path to the credentials file.
+	//
+	// If empty will look for "TWITTER_CREDENTIALS_FILE" env variable. If the
+	// env value is empty will default to current user's home directory.
+	// Linux/OSX: "$HOME/.twitter/credentials"
+	// Windows:   "%USERPROFILE%\.twitter\credentials"
+	Filename string
+
+	// Twitter profile to extract credentials from the credentials file.
+	//
+	// If empty will look for "TWITTER_PROFILE" env variable. If the env value
+	// is empty will default to "default".
+	Profile string
+
+	retrieved bool
+	modTime   time.Time
+	size      int64
+}
+
+// fileProfile is a profile of a credentials file.
+type fileProfile struct {
+	BearerToken    string `yaml:"bearer_token"`
+	ConsumerKey    string `yaml:"consumer_key"`
+	ConsumerSecret string `yaml:"consumer_secret"`
+	AccessToken    string `yaml:"access_token"`
+	AccessSecret   string `yaml:"access_secret"`
+}
+
+// NewFileCredentials returns a pointer to a new Credentials object
+// wrapping the profile file provider.
+func NewFileCredentials(filename, profile string) *Credentials {
+	return NewCredentialsFromProvider(&FileProvider{
+		Filename: filename,
+		Profile:  profile,
+	})
+}
+
+// Retrieve reads and extracts the credentials from the credentials file.
+func (p *FileProvider) Retrieve() (Value, error) {
+	p.retrieved = false
+
+	filename, err := p.filename()
+	if err != nil {
+		return Value{ProviderName: FileProviderName}, err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return Value{ProviderName: FileProviderName}, newProviderError(FileProviderName, "failed to load credentials file", err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Value{ProviderName: FileProviderName}, newProviderError(FileProviderName, "failed to load credentials file", err)
+	}
+
+	// JSON is a subset of YAML, so both formats are read by the YAML decoder.
+	profiles := map[string]fileProfile{}
+	if err := yaml.Unmarshal(b, &profiles); err != nil {
+		return Value{ProviderName: FileProviderName}, newProviderError(FileProviderName, "failed to decode credentials file", err)
+	}
+	profile, ok := profiles[p.profile()]
+	if !ok {
+		return Value{ProviderName: FileProviderName}, newProviderError(FileProviderName, "credentials file has no profile "+p.profile(), nil)
+	}
+
+	v := Value{
+		BearerToken:    profile.BearerToken,
+		ConsumerKey:    profile.ConsumerKey,
+		ConsumerSecret: profile.ConsumerSecret,
+		AccessToken:    profile.AccessToken,
+		AccessSecret:   profile.AccessSecret,
+		ProviderName:   FileProviderName,
+	}
+	if v.isEmpty() {
+		return Value{ProviderName: FileProviderName}, newProviderError(FileProviderName, "profile "+p.profile()+" has no credentials", ErrCredentialsEmpty)
+	}
+
+	p.retrieved = true
+	p.modTime, p.size = info.ModTime(), info.Size()
+	return v, nil
+}
+
+// IsExpired returns if the credentials have not been retrieved, or the
+// credentials file changed since.
+func (p *FileProvider) IsExpired() bool {
+	if !p.retrieved {
+		return true
+	}
+	info, err := os.Stat(p.Filename)
+	if err != nil {
+		return true
+	}
+	return !info.ModTime().Equal(p.modTime) || info.Size() != p.size
+}
+
+// filename returns the filename to use to read the credentials.
+func (p *FileProvider) filename() (string, error) {
+	if len(p.Filename) != 0 {
+		return p.Filename, nil
+	}
+	if p.Filename = os.Getenv(EnvCredentialsFile); len(p.Filename) != 0 {
+		return p.Filename, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", newProviderError(FileProviderName, "user home directory not found", err)
+	}
+	p.Filename = filepath.Join(home, ".twitter", "credentials")
+	return p.Filename, nil
+}
+
+// profile returns the Twitter credentials profile. If empty will read the
+// environment variable "TWITTER_PROFILE". If that is not set "default" will
+// be returned.
+func (p *FileProvider) profile() string {
+	if p.Profile == "" {
+		p.Profile = os.Getenv(EnvProfile)
+	}
+	if p.Profile == "" {
+		p.Profile = DefaultProfile
+	}
+	return p.Profile
+}
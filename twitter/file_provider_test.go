@@ -0,0 +1,108 @@
+package twitter
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCredentialsFile(t *testing.T, name, content string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestFileProviderProfiles(t *testing.T) {
+	yamlFile := writeCredentialsFile(t, "credentials", `
+default:
+  bearer_token: default-bearer
+bot:
+  consumer_key: key
+  consumer_secret: secret
+  access_token: token
+  access_secret: token-secret
+`)
+	jsonFile := writeCredentialsFile(t, "credentials.json", `{
+	"default": {"bearer_token": "default-bearer"},
+	"bot": {"consumer_key": "key", "consumer_secret": "secret", "access_token": "token", "access_secret": "token-secret"}
+}`)
+
+	for _, filename := range []string{yamlFile, jsonFile} {
+		p := &FileProvider{Filename: filename}
+		value, err := p.Retrieve()
+		assert.Nil(t, err, filename)
+		assert.Equal(t, "default-bearer", value.BearerToken, filename)
+		assert.Equal(t, FileProviderName, value.ProviderName, filename)
+		assert.False(t, p.IsExpired(), filename)
+
+		value, err = (&FileProvider{Filename: filename, Profile: "bot"}).Retrieve()
+		assert.Nil(t, err, filename)
+		assert.Equal(t, "", value.BearerToken, filename)
+		assert.Equal(t, Value{
+			ConsumerKey:    "key",
+			ConsumerSecret: "secret",
+			AccessToken:    "token",
+			AccessSecret:   "token-secret",
+			ProviderName:   FileProviderName,
+		}, value, filename)
+	}
+}
+
+func TestFileProviderEnvDefaults(t *testing.T) {
+	filename := writeCredentialsFile(t, "credentials", "bot:\n  bearer_token: bot-bearer\n")
+	setEnv(t, map[string]string{
+		EnvCredentialsFile: filename,
+		EnvProfile:         "bot",
+	})
+
+	value, err := (&FileProvider{}).Retrieve()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "bot-bearer", value.BearerToken)
+}
+
+func TestFileProviderErrors(t *testing.T) {
+	filename := writeCredentialsFile(t, "credentials", "default:\n  bearer_token: bearer\nempty: {}\n")
+
+	cases := []*FileProvider{
+		{Filename: filepath.Join(t.TempDir(), "missing")},
+		{Filename: writeCredentialsFile(t, "invalid", "default: [")},
+		{Filename: filename, Profile: "missing"},
+		{Filename: filename, Profile: "empty"},
+	}
+
+	for _, p := range cases {
+		_, err := p.Retrieve()
+		var providerErr *ProviderError
+		assert.True(t, errors.As(err, &providerErr), p.Filename)
+		assert.True(t, p.IsExpired(), p.Filename)
+	}
+}
+
+func TestFileProviderExpiresOnChange(t *testing.T) {
+	filename := writeCredentialsFile(t, "credentials", "default:\n  bearer_token: bearer\n")
+	creds := NewFileCredentials(filename, "")
+
+	value, err := creds.Retrieve()
+	assert.Nil(t, err)
+	assert.Equal(t, "bearer", value.BearerToken)
+
+	if err := ioutil.WriteFile(filename, []byte("default:\n  bearer_token: rotated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err = creds.Retrieve()
+	assert.Nil(t, err)
+	assert.Equal(t, "rotated", value.BearerToken)
+}
@@ -0,0 +1,210 @@
+package twitter
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrTokenStoreDecrypt is returned when the token file can not be
+	// decrypted, because it was encrypted with another key or was tampered
+	// with.
+	ErrTokenStoreDecrypt = errors.New("TokenStoreDecrypt: failed to decrypt token file, the key is wrong or the file was modified")
+)
+
+// A FileTokenStore is a TokenStore which keeps tokens in a single file,
+// encrypted at rest with AES-GCM using a user supplied key.
+//
+// Every write replaces the file atomically by writing a temporary file in the
+// same directory and renaming it over the token file, so readers in other
+// processes never observe a partially written file. Writes hold an advisory
+// lock on the sidecar file "<filename>.lock" from reading the file until it is
+// replaced, so writes from concurrent processes do not lose each other's
+// tokens.
+type FileTokenStore struct {
+	filename string
+	aead     cipher.AEAD
+	m        sync.Mutex
+}
+
+// NewFileTokenStore returns a pointer to a new FileTokenStore keeping tokens in
+// the file, encrypted with the key. The key must be 16, 24 or 32 bytes long to
+// select AES-128, AES-192 or AES-256. The file is created on the first write.
+func NewFileTokenStore(filename string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTokenStore{
+		filename: filename,
+		aead:     aead,
+	}, nil
+}
+
+// Get returns the token stored for the account key.
+func (s *FileTokenStore) Get(ctx context.Context, key string) (*OAuth2Token, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	token, ok := tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// Put stores the token for the account key.
+func (s *FileTokenStore) Put(ctx context.Context, key string, token *OAuth2Token) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return s.save(tokens)
+}
+
+// Delete removes the token stored for the account key.
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+	delete(tokens, key)
+	return s.save(tokens)
+}
+
+// lock takes the advisory lock on the sidecar lock file of the token file,
+// which is shared by every process using the file, and returns the function
+// releasing it.
+func (s *FileTokenStore) lock() (func(), error) {
+	f, err := os.OpenFile(s.filename+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// load reads and decrypts the tokens of the file. A missing file holds no
+// tokens.
+func (s *FileTokenStore) load() (map[string]*OAuth2Token, error) {
+	tokens := make(map[string]*OAuth2Token)
+
+	b, err := ioutil.ReadFile(s.filename)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(b) < nonceSize {
+		return nil, ErrTokenStoreDecrypt
+	}
+	plaintext, err := s.aead.Open(nil, b[:nonceSize], b[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrTokenStoreDecrypt
+	}
+
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// save encrypts the tokens with a new nonce and atomically replaces the file
+// with them. The nonce is prepended to the ciphertext.
+func (s *FileTokenStore) save(tokens map[string]*OAuth2Token) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	ciphertext := s.aead.Seal(nonce, nonce, plaintext, nil)
+
+	return writeFileAtomic(s.filename, ciphertext, 0600)
+}
+
+// writeFileAtomic writes the data to a temporary file in the directory of the
+// file, syncs it and renames it over the file.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
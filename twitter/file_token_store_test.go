@@ -0,0 +1,122 @@
+package twitter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTokenStoreKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "tokens")
+	store, err := NewFileTokenStore(filename, testTokenStoreKey)
+	assert.Nil(t, err)
+
+	_, err = store.Get(ctx, "2244994945")
+	assert.Equal(t, ErrTokenNotFound, err)
+
+	expiry := time.Now().Add(time.Hour).Round(time.Second)
+	assert.Nil(t, store.Put(ctx, "2244994945", &OAuth2Token{AccessToken: "secret-access", RefreshToken: "secret-refresh", Expiry: expiry}))
+	assert.Nil(t, store.Put(ctx, "783214", &OAuth2Token{AccessToken: "other"}))
+
+	b, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.False(t, bytes.Contains(b, []byte("secret-access")))
+	assert.False(t, bytes.Contains(b, []byte("secret-refresh")))
+
+	// A new store with the same key reads the tokens back, as after a restart.
+	reopened, err := NewFileTokenStore(filename, testTokenStoreKey)
+	assert.Nil(t, err)
+	token, err := reopened.Get(ctx, "2244994945")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret-refresh", token.RefreshToken)
+	assert.True(t, expiry.Equal(token.Expiry))
+
+	assert.Nil(t, reopened.Delete(ctx, "2244994945"))
+	_, err = store.Get(ctx, "2244994945")
+	assert.Equal(t, ErrTokenNotFound, err)
+	_, err = store.Get(ctx, "783214")
+	assert.Nil(t, err)
+
+	// Only the token file and its lock file remain.
+	files, _ := ioutil.ReadDir(filepath.Dir(filename))
+	assert.Equal(t, 2, len(files), "temporary files are left behind")
+}
+
+func TestFileTokenStoreWrongKey(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "tokens")
+	store, _ := NewFileTokenStore(filename, testTokenStoreKey)
+	assert.Nil(t, store.Put(ctx, "2244994945", &OAuth2Token{AccessToken: "access"}))
+
+	other, err := NewFileTokenStore(filename, []byte("fedcba9876543210fedcba9876543210"))
+	assert.Nil(t, err)
+	_, err = other.Get(ctx, "2244994945")
+	assert.Equal(t, ErrTokenStoreDecrypt, err)
+
+	_, err = NewFileTokenStore(filename, []byte("short"))
+	assert.NotNil(t, err)
+}
+
+func TestFileTokenStoreConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "tokens")
+
+	// Separate stores stand in for separate processes sharing the file.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		store, _ := NewFileTokenStore(filename, testTokenStoreKey)
+		wg.Add(1)
+		go func(i int, store *FileTokenStore) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				key := fmt.Sprintf("%d-%d", i, j)
+				assert.Nil(t, store.Put(ctx, key, &OAuth2Token{AccessToken: key}))
+			}
+		}(i, store)
+	}
+	wg.Wait()
+
+	store, _ := NewFileTokenStore(filename, testTokenStoreKey)
+	_, err := store.Get(ctx, "0-0")
+	assert.True(t, err == nil || err == ErrTokenNotFound, "token file is corrupted: %v", err)
+}
+
+func TestFileTokenStoreConcurrentStoresKeepEntries(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "tokens")
+	first, _ := NewFileTokenStore(filename, testTokenStoreKey)
+	second, _ := NewFileTokenStore(filename, testTokenStoreKey)
+
+	var wg sync.WaitGroup
+	for i, store := range []*FileTokenStore{first, second} {
+		wg.Add(1)
+		go func(i int, store *FileTokenStore) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				key := fmt.Sprintf("%d-%d", i, j)
+				assert.Nil(t, store.Put(ctx, key, &OAuth2Token{AccessToken: key}))
+			}
+		}(i, store)
+	}
+	wg.Wait()
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 20; j++ {
+			key := fmt.Sprintf("%d-%d", i, j)
+			token, err := first.Get(ctx, key)
+			if assert.Nil(t, err, key) {
+				assert.Equal(t, key, token.AccessToken)
+			}
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package twitter
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -41,8 +43,14 @@ type ValidateRulesOutputMeta struct {
 
 // ValidateRulesOutput contains output of validating rules endpoint response
 type ValidateRulesOutput struct {
-	Data []Rule                  `json:"data"`
-	Meta ValidateRulesOutputMeta `json:"meta"`
+	Data   []Rule                  `json:"data"`
+	Meta   ValidateRulesOutputMeta `json:"meta"`
+	Errors []PartialError          `json:"errors,omitempty"`
+}
+
+// PartialErrors returns the errors returned for rules which are not valid
+func (o *ValidateRulesOutput) PartialErrors() []PartialError {
+	return o.Errors
 }
 
 // CreateRulesInput is used to create a set of rules
@@ -52,8 +60,14 @@ type CreateRulesInput struct {
 
 // CreateRulesOutput contains output of creating rules endpoint response
 type CreateRulesOutput struct {
-	Data []Rule                  `json:"data"`
-	Meta ValidateRulesOutputMeta `json:"meta"`
+	Data   []Rule                  `json:"data"`
+	Meta   ValidateRulesOutputMeta `json:"meta"`
+	Errors []PartialError          `json:"errors,omitempty"`
+}
+
+// PartialErrors returns the errors returned for rules which were not created
+func (o *CreateRulesOutput) PartialErrors() []PartialError {
+	return o.Errors
 }
 
 // RulesIDs contains a list of rule ids to be deleted
@@ -68,7 +82,13 @@ type DeleteRulesInput struct {
 
 // DeleteRulesOuput contains output of deleting rules endpoint response
 type DeleteRulesOuput struct {
-	Meta DeleteRulesOutputMeta `json:"meta"`
+	Meta   DeleteRulesOutputMeta `json:"meta"`
+	Errors []PartialError        `json:"errors,omitempty"`
+}
+
+// PartialErrors returns the errors returned for rules which were not deleted
+func (o *DeleteRulesOuput) PartialErrors() []PartialError {
+	return o.Errors
 }
 
 // DeleteRulesOutputMeta contains meta information about deleting rules endpoint response
@@ -86,11 +106,35 @@ type DeleteRulesOutputMetaSummary struct {
 // GetRulesInput contains input request to retrieving rules endpoint
 type GetRulesInput struct {
 	IDs []string `json:"ids"`
+
+	// The maximum number of rules to return per page, between 1 and 1000.
+	MaxResults int `json:"max_results,omitempty"`
+
+	// The next token of the previous page, to get the page after it.
+	PaginationToken string `json:"pagination_token,omitempty"`
+}
+
+// GetRulesOutputMeta contains meta information about retrieving rules endpoint response
+type GetRulesOutputMeta struct {
+	Sent time.Time `json:"sent"`
+	PaginationMeta
 }
 
 // GetRulesOutput contains output of retrieving rules endpoint response
 type GetRulesOutput struct {
-	Data []Rule `json:"data"`
+	Data   []Rule             `json:"data"`
+	Meta   GetRulesOutputMeta `json:"meta"`
+	Errors []PartialError     `json:"errors,omitempty"`
+}
+
+// NextPageToken returns the token of the next page of rules
+func (o *GetRulesOutput) NextPageToken() string {
+	return o.Meta.NextToken
+}
+
+// PartialErrors returns the errors returned for rules which could not be retrieved
+func (o *GetRulesOutput) PartialErrors() []PartialError {
+	return o.Errors
 }
 
 // StreamTweetsInput contains input query parameters to include in the request
@@ -105,14 +149,27 @@ type StreamTweetsInput struct {
 
 // StreamTweetsOutput contains streaming endpoint output
 type StreamTweetsOutput struct {
-	Data          Tweet        `json:"data"`
-	Includes      Includes     `json:"includes"`
-	Errors        []Diagnostic `json:"errors"`
-	MatchingRules []Rule       `json:"matching_rules"`
+	Data          Tweet          `json:"data"`
+	Includes      Includes       `json:"includes"`
+	Errors        []PartialError `json:"errors"`
+	MatchingRules []Rule         `json:"matching_rules"`
+}
+
+// PartialErrors returns the errors returned for referenced objects which could not be expanded
+func (o *StreamTweetsOutput) PartialErrors() []PartialError {
+	return o.Errors
+}
+
+// A StreamTweetsMessage is a message received on a stream of Tweets.
+type StreamTweetsMessage struct {
+	StreamMessage
+
+	// Data is the Tweet the message was deserialized to.
+	Data *StreamTweetsOutput
 }
 
 // ValidateRules tests the syntax of your rule without submitting it
-func (c *Client) ValidateRules(input *ValidateRulesInput) (req *Request, output *ValidateRulesOutput) {
+func (c *Client) ValidateRules(input *ValidateRulesInput, opts ...Option) (req *Request, output *ValidateRulesOutput) {
 	queryParams := make(map[string]string)
 	queryParams["dry_run"] = "true"
 
@@ -121,6 +178,8 @@ func (c *Client) ValidateRules(input *ValidateRulesInput) (req *Request, output
 		HTTPMethod:  "POST",
 		HTTPPath:    "tweets/search/stream/rules",
 		QueryParams: queryParams,
+		Idempotent:  Bool(true),
+		AuthTypes:   []AuthType{AuthAppOnly},
 	}
 
 	if input == nil {
@@ -129,15 +188,18 @@ func (c *Client) ValidateRules(input *ValidateRulesInput) (req *Request, output
 
 	output = &ValidateRulesOutput{}
 	req = c.NewRequest(endpoint, input, output)
+	req.ApplyOptions(opts...)
 	return
 }
 
 // CreateRules adds rules to your stream
-func (c *Client) CreateRules(input *CreateRulesInput) (req *Request, output *CreateRulesOutput) {
+func (c *Client) CreateRules(input *CreateRulesInput, opts ...Option) (req *Request, output *CreateRulesOutput) {
 	endpoint := &EndPointInfo{
 		Name:       createRules,
 		HTTPMethod: "POST",
 		HTTPPath:   "tweets/search/stream/rules",
+		Idempotent: Bool(false),
+		AuthTypes:  []AuthType{AuthAppOnly},
 	}
 
 	if input == nil {
@@ -147,15 +209,18 @@ func (c *Client) CreateRules(input *CreateRulesInput) (req *Request, output *Cre
 
 	output = &CreateRulesOutput{}
 	req = c.NewRequest(endpoint, input, output)
+	req.ApplyOptions(opts...)
 	return
 }
 
 // DeleteRules removes rules from your stream
-func (c *Client) DeleteRules(input *DeleteRulesInput) (req *Request, output *DeleteRulesOuput) {
+func (c *Client) DeleteRules(input *DeleteRulesInput, opts ...Option) (req *Request, output *DeleteRulesOuput) {
 	endpoint := &EndPointInfo{
 		Name:       deleteRules,
 		HTTPMethod: "POST",
 		HTTPPath:   "tweets/search/stream/rules",
+		Idempotent: Bool(true),
+		AuthTypes:  []AuthType{AuthAppOnly},
 	}
 
 	if input == nil {
@@ -164,52 +229,183 @@ func (c *Client) DeleteRules(input *DeleteRulesInput) (req *Request, output *Del
 
 	output = &DeleteRulesOuput{}
 	req = c.NewRequest(endpoint, input, output)
+	req.ApplyOptions(opts...)
 	return
 }
 
-// GetRules retrives rules that have been applied to your stream
-func (c *Client) GetRules(input *GetRulesInput) (req *Request, output *GetRulesOutput) {
+// GetRules retrives rules that have been applied to your stream. More than
+// MaxIDsPerRequest IDs are looked up in chunks, one after the other, when the
+// request is sent, and the outputs of the chunks are merged into the output.
+// Use GetRulesChunked to send the chunks in parallel.
+func (c *Client) GetRules(input *GetRulesInput, opts ...Option) (req *Request, output *GetRulesOutput) {
+	if input == nil {
+		input = &GetRulesInput{}
+	}
+
 	queryParams := make(map[string]string)
 	if len(input.IDs) > 0 {
 		queryParams["ids"] = strings.Join(input.IDs, ",")
 	}
+	if input.MaxResults > 0 {
+		queryParams["max_results"] = strconv.Itoa(input.MaxResults)
+	}
+	if input.PaginationToken != "" {
+		queryParams["pagination_token"] = input.PaginationToken
+	}
 
 	endpoint := &EndPointInfo{
 		Name:        getRules,
 		HTTPMethod:  "GET",
 		HTTPPath:    "tweets/search/stream/rules",
 		QueryParams: queryParams,
-	}
-
-	if input == nil {
-		input = &GetRulesInput{}
+		AuthTypes:   []AuthType{AuthAppOnly},
 	}
 
 	output = &GetRulesOutput{}
 	req = c.NewRequest(endpoint, input, output)
+	req.ApplyOptions(opts...)
+	if len(input.IDs) > MaxIDsPerRequest && req.Error == nil {
+		// The IDs are looked up in chunks of MaxIDsPerRequest once the
+		// request is sent.
+		lookup := &ChunkedLookup{
+			IDs: input.IDs,
+			NewRequest: func(ids []string) *Request {
+				inCpy := *input
+				inCpy.IDs = ids
+				req, _ := c.GetRules(&inCpy, opts...)
+				return req
+			},
+		}
+		lookup.sendInChunks(req)
+	}
 	return
 
 }
 
+// GetRulesChunked retrieves the rules of any number of IDs, by sending the
+// IDs in chunks of MaxIDsPerRequest and merging the outputs. Chunks are sent
+// in parallel with the executor, or one after the other if it is nil.
+func (c *Client) GetRulesChunked(ctx context.Context, input *GetRulesInput, executor *BatchExecutor, opts ...Option) (*GetRulesOutput, error) {
+	if input == nil || len(input.IDs) == 0 {
+		// Without IDs all rules are retrieved by a single request.
+		req, output := c.GetRules(input, opts...)
+		return output, req.SendWithContext(ctx)
+	}
+
+	lookup := &ChunkedLookup{
+		IDs:      input.IDs,
+		Executor: executor,
+		NewRequest: func(ids []string) *Request {
+			inCpy := *input
+			inCpy.IDs = ids
+			req, _ := c.GetRules(&inCpy, opts...)
+			return req
+		},
+	}
+	output := &GetRulesOutput{}
+	err := lookup.Send(ctx, output)
+	return output, err
+}
+
+// GetRulesPages iterates over the pages of a GetRules operation, calling fn
+// with each page until there are no more pages, or fn returns false.
+//
+//	err := client.GetRulesPages(&twitter.GetRulesInput{MaxResults: 100},
+//	    func(page *twitter.GetRulesOutput, lastPage bool) bool {
+//	        rules = append(rules, page.Data...)
+//	        return true
+//	    })
+func (c *Client) GetRulesPages(input *GetRulesInput, fn func(*GetRulesOutput, bool) bool, opts ...Option) error {
+	return c.GetRulesPagesWithContext(backgroundCtx, input, fn, opts...)
+}
+
+// GetRulesPagesWithContext is the same as GetRulesPages with the addition of
+// a context, which is set on every page request.
+func (c *Client) GetRulesPagesWithContext(ctx context.Context, input *GetRulesInput, fn func(*GetRulesOutput, bool) bool, opts ...Option) error {
+	p := c.GetRulesPagination(ctx, input, opts...)
+	return p.EachPage(func(page interface{}, lastPage bool) bool {
+		return fn(page.(*GetRulesOutput), lastPage)
+	})
+}
+
+// GetRulesPagination returns a Pagination over the pages of a GetRules
+// operation, which starts at the input's pagination token. Items are Rule
+// values.
+func (c *Client) GetRulesPagination(ctx context.Context, input *GetRulesInput, opts ...Option) *Pagination {
+	p := &Pagination{
+		NewRequest: func(token string) (*Request, error) {
+			var inCpy GetRulesInput
+			if input != nil {
+				inCpy = *input
+			}
+			inCpy.PaginationToken = token
+			req, _ := c.GetRules(&inCpy, opts...)
+			req.SetContext(ctx)
+			return req, nil
+		},
+	}
+	if input != nil {
+		p.Token = input.PaginationToken
+	}
+	return p
+}
+
 // StreamTweets streams Tweets in real-time based on a specific set of filter rules.
-// You need to check if stream is established by checking `receiving` parameter of the
-// Stream struct. Streaming tweets can be accessed through the Queue on the return
-// stream struct
-func (c *Client) StreamTweets(input StreamTweetsInput) (s *Stream) {
+// The returned stream connects in the background as soon as it is created.
+// With the WithManualStart option it is connected with Connect instead, which
+// returns the error the stream could not be established with.
+// Streaming tweets can be accessed through the MessageQueue, the Messages
+// channel, or typed through the Tweets channel, of the returned stream struct
+func (c *Client) StreamTweets(input StreamTweetsInput, opts ...StreamOption) (s *Stream) {
+	return c.StreamTweetsWithContext(backgroundCtx, input, opts...)
+}
+
+// StreamTweetsWithContext is the same as StreamTweets with the addition of a
+// context. The stream stops once the context is done.
+func (c *Client) StreamTweetsWithContext(ctx context.Context, input StreamTweetsInput, opts ...StreamOption) (s *Stream) {
 	queryParams := getQueryParamsFromStreamTweetsInput(input)
 	endpoint := &EndPointInfo{
 		Name:        streamTweets,
 		HTTPMethod:  "GET",
 		HTTPPath:    "tweets/search/stream",
 		QueryParams: queryParams,
+		AuthTypes:   []AuthType{AuthAppOnly},
 	}
 
 	output := &StreamTweetsOutput{}
-	s = c.NewStream(endpoint, nil, output)
+	s = c.NewStreamWithContext(ctx, endpoint, nil, output, opts...)
 	return
 
 }
 
+// Tweets returns a channel of the messages of a stream of Tweets, which is
+// closed along with the stream's MessageQueue. Messages are read from the
+// Messages channel, so a stream should be consumed with one of Tweets,
+// Messages or its MessageQueue.
+func (s *Stream) Tweets() <-chan *StreamTweetsMessage {
+	s.tweetsOnce.Do(func() {
+		s.tweets = make(chan *StreamTweetsMessage)
+		go func() {
+			defer close(s.tweets)
+			for message := range s.messages {
+				data, ok := message.Data.(*StreamTweetsOutput)
+				if !ok {
+					continue
+				}
+
+				select {
+				case s.tweets <- &StreamTweetsMessage{StreamMessage: *message, Data: data}:
+				case <-s.done:
+					return
+				case <-s.Context().Done():
+					return
+				}
+			}
+		}()
+	})
+	return s.tweets
+}
+
 func getQueryParamsFromStreamTweetsInput(input StreamTweetsInput) map[string]string {
 	queryParams := make(map[string]string, 0)
 	fields := reflect.Indirect(reflect.ValueOf(&input))
@@ -3,6 +3,7 @@ package twitter
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 func (suite *twitterClientSuite) Test_ValidateRules() {
@@ -100,7 +101,7 @@ func (suite *twitterClientSuite) Test_GetRules() {
 		fmt.Fprintf(w, `{"data": [{"id": "1165037377523306497", "value": "dog has:images", "tag": "dog pictures"}, {"id": "1165037377523306498", "value": "cat has:images -grumpy"}], "meta": {"sent": "2019-08-29T01:12:10.729Z"}}`)})
 
 	input := &GetRulesInput{
-		[]string{
+		IDs: []string{
 			"1165037377523306497",
 			"1165037377523306498",
 		},
@@ -134,3 +135,52 @@ func (suite *twitterClientSuite) Test_StreamTweets() {
 		stream.Stop()
 		suite.Assert().Equal(len(stream.MessageQueue), 0)
 }
+
+func (suite *twitterClientSuite) Test_StreamTweetsTyped() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}, "matching_rules": [{"id": "1", "tag": "api"}]}`+"\r\n")
+	})
+
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	var messages []*StreamTweetsMessage
+	for message := range stream.Tweets() {
+		messages = append(messages, message)
+	}
+	stream.Stop()
+
+	suite.Require().Equal(1, len(messages))
+	suite.Assert().Equal("1067094924124872705", messages[0].Data.Data.ID)
+	suite.Assert().Equal("api", messages[0].Data.MatchingRules[0].Tag)
+	suite.Assert().Equal(1, messages[0].ConnectionID)
+	suite.Assert().NotEmpty(messages[0].Raw)
+	suite.Assert().WithinDuration(time.Now(), messages[0].ReceivedAt, time.Second)
+}
+
+func (suite *twitterClientSuite) Test_ClientLiteral() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": [{"id": "1165037377523306497", "value": "dog has:images"}]}`)
+	})
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+	})
+
+	// A client built without NewClient uses the default handlers.
+	client := &Client{
+		Config:  suite.client.Config,
+		APIInfo: suite.client.APIInfo,
+	}
+
+	req, out := client.GetRules(&GetRulesInput{})
+	suite.Require().Nil(req.Send())
+	suite.Assert().Equal(1, len(out.Data))
+
+	stream := client.StreamTweets(StreamTweetsInput{})
+	messages := 0
+	for range stream.MessageQueue {
+		messages++
+	}
+	stream.Stop()
+	suite.Assert().Equal(1, messages)
+}
@@ -0,0 +1,200 @@
+package twitter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	oauth1SignatureMethod = "HMAC-SHA1"
+	oauth1Version         = "1.0"
+)
+
+// OAuth1Signer is a request handler which signs the request with the OAuth
+// 1.0a user context credentials, even if a bearer token is set as well.
+var OAuth1Signer = HandlerFunction{
+	Name: "OAuth1Signer",
+	Fn: func(r *Request) {
+		value, err := r.retrieveCredentials()
+		if err != nil {
+			r.Error = err
+			return
+		}
+		if !value.HasOAuth1() {
+			r.Error = ErrOAuth1CredentialsEmpty
+			return
+		}
+		r.Error = signOAuth1(r.HTTPRequest, value)
+	},
+}
+
+// signOAuth1 sets the OAuth 1.0a Authorization header on the HTTP request
+// using a new nonce and the current time.
+func signOAuth1(req *http.Request, value Value) error {
+	nonce, err := oauth1Nonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header, err := oauth1AuthorizationHeader(req, value, nonce, timestamp)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", header)
+	return nil
+}
+
+// oauth1AuthorizationHeader returns the OAuth 1.0a Authorization header value
+// for the HTTP request, signed with HMAC-SHA1 over the method, URL, query
+// and form parameters as described in RFC 5849.
+func oauth1AuthorizationHeader(req *http.Request, value Value, nonce, timestamp string) (string, error) {
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     value.ConsumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": oauth1SignatureMethod,
+		"oauth_timestamp":        timestamp,
+		"oauth_token":            value.AccessToken,
+		"oauth_version":          oauth1Version,
+	}
+
+	params, err := oauth1RequestParams(req)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range oauthParams {
+		params.Add(k, v)
+	}
+
+	base := oauth1SignatureBase(req.Method, req.URL, params)
+	oauthParams["oauth_signature"] = oauth1Signature(base, value.ConsumerSecret, value.AccessSecret)
+
+	keys := make([]string, 0, len(oauthParams))
+	for k := range oauthParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, oauth1Escape(k), oauth1Escape(oauthParams[k]))
+	}
+	return "OAuth " + strings.Join(pairs, ", "), nil
+}
+
+// oauth1RequestParams returns the query parameters of the HTTP request, plus
+// its body parameters if the body is form encoded. JSON bodies are not part
+// of the signature.
+func oauth1RequestParams(req *http.Request) (url.Values, error) {
+	params := url.Values{}
+	for k, vs := range req.URL.Query() {
+		params[k] = append(params[k], vs...)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" || req.GetBody == nil {
+		return params, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(b))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range form {
+		params[k] = append(params[k], vs...)
+	}
+	return params, nil
+}
+
+// oauth1SignatureBase returns the signature base string of the request.
+func oauth1SignatureBase(method string, u *url.URL, params url.Values) string {
+	pairs := make([]string, 0, len(params))
+	for k, vs := range params {
+		for _, v := range vs {
+			pairs = append(pairs, oauth1Escape(k)+"="+oauth1Escape(v))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		oauth1Escape(oauth1BaseURL(u)),
+		oauth1Escape(strings.Join(pairs, "&")),
+	}, "&")
+}
+
+// oauth1BaseURL returns the URL without query, fragment and default port,
+// with a lower case scheme and host.
+func oauth1BaseURL(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		host = strings.ToLower(u.Hostname())
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// oauth1Signature returns the base64 encoded HMAC-SHA1 signature of the
+// signature base string.
+func oauth1Signature(base, consumerSecret, tokenSecret string) string {
+	key := oauth1Escape(consumerSecret) + "&" + oauth1Escape(tokenSecret)
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauth1Nonce returns a random alphanumeric nonce.
+func oauth1Nonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return -1
+	}, base64.StdEncoding.EncodeToString(b)), nil
+}
+
+// oauth1Escape percent encodes the string as described in RFC 3986, which
+// only leaves the unreserved characters unescaped.
+func oauth1Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
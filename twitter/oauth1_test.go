@@ -0,0 +1,83 @@
+package twitter
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vector published in the Twitter API documentation, "Creating a signature".
+func TestOAuth1Signature_TwitterVector(t *testing.T) {
+	value := Value{
+		ConsumerKey:    "xvz1evFS4wEEPTGEFPHBog",
+		ConsumerSecret: "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw",
+		AccessToken:    "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		AccessSecret:   "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE",
+	}
+
+	form := url.Values{"status": {"Hello Ladies + Gentlemen, a signed OAuth request!"}}
+	req, _ := http.NewRequest("POST", "https://api.twitter.com/1.1/statuses/update.json?include_entities=true", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	params, err := oauth1RequestParams(req)
+	assert.Nil(t, err)
+	params.Set("oauth_consumer_key", value.ConsumerKey)
+	params.Set("oauth_nonce", "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg")
+	params.Set("oauth_signature_method", "HMAC-SHA1")
+	params.Set("oauth_timestamp", "1318622958")
+	params.Set("oauth_token", value.AccessToken)
+	params.Set("oauth_version", "1.0")
+
+	base := oauth1SignatureBase(req.Method, req.URL, params)
+	assert.Equal(t, "POST&https%3A%2F%2Fapi.twitter.com%2F1.1%2Fstatuses%2Fupdate.json&include_entities%3Dtrue%26oauth_consumer_key%3Dxvz1evFS4wEEPTGEFPHBog%26oauth_nonce%3DkYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D1318622958%26oauth_token%3D370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb%26oauth_version%3D1.0%26status%3DHello%2520Ladies%2520%252B%2520Gentlemen%252C%2520a%2520signed%2520OAuth%2520request%2521", base)
+
+	header, err := oauth1AuthorizationHeader(req, value, "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg", "1318622958")
+	assert.Nil(t, err)
+	assert.Contains(t, header, `oauth_signature="hCtSmYh%2BiHYCEqBWrE7C7hYmtUk%3D"`)
+	assert.True(t, strings.HasPrefix(header, `OAuth oauth_consumer_key="xvz1evFS4wEEPTGEFPHBog", oauth_nonce=`))
+}
+
+// Test vector from RFC 5849, section 1.2.
+func TestOAuth1Signature_RFC5849Vector(t *testing.T) {
+	value := Value{
+		ConsumerKey:    "dpf43f3p2l4k3l03",
+		ConsumerSecret: "kd94hf93k423kf44",
+		AccessToken:    "nnch734d00sl2jdk",
+		AccessSecret:   "pfkkdhi9sl3r4s00",
+	}
+
+	req, _ := http.NewRequest("GET", "http://photos.example.net/photos?file=vacation.jpg&size=original", nil)
+	params, err := oauth1RequestParams(req)
+	assert.Nil(t, err)
+	params.Set("oauth_consumer_key", value.ConsumerKey)
+	params.Set("oauth_token", value.AccessToken)
+	params.Set("oauth_signature_method", "HMAC-SHA1")
+	params.Set("oauth_timestamp", "137131202")
+	params.Set("oauth_nonce", "chapoH")
+
+	base := oauth1SignatureBase(req.Method, req.URL, params)
+	assert.Equal(t, "MdpQcU8iPSUjWoN/UDMsK2sui9I=", oauth1Signature(base, value.ConsumerSecret, value.AccessSecret))
+}
+
+func (suite *twitterClientSuite) Test_OAuth1Signer() {
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		suite.Assert().True(strings.HasPrefix(authorization, "OAuth "))
+		suite.Assert().Contains(authorization, `oauth_consumer_key="key"`)
+		suite.Assert().Contains(authorization, `oauth_token="token"`)
+		w.Write([]byte(`{"data": []}`))
+	})
+
+	suite.client.Config.WithCredentials(NewCredentials(Value{
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		AccessToken:    "token",
+		AccessSecret:   "token-secret",
+	}))
+
+	req := suite.newUserContextRequest()
+	suite.Assert().Nil(req.Send())
+}
@@ -0,0 +1,279 @@
+package twitter
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Twitter OAuth 2.0 Authorization Code with PKCE flow endpoints
+const (
+	OAuth2AuthURL  = "https://twitter.com/i/oauth2/authorize"
+	OAuth2TokenURL = EndPoint + "/2/oauth2/token"
+)
+
+// OAuth 2.0 scopes which can be requested for user context access tokens
+const (
+	ScopeTweetRead      = "tweet.read"
+	ScopeTweetWrite     = "tweet.write"
+	ScopeUsersRead      = "users.read"
+	ScopeFollowsRead    = "follows.read"
+	ScopeFollowsWrite   = "follows.write"
+	ScopeLikeRead       = "like.read"
+	ScopeLikeWrite      = "like.write"
+	ScopeListRead       = "list.read"
+	ScopeListWrite      = "list.write"
+	ScopeBookmarkRead   = "bookmark.read"
+	ScopeBookmarkWrite  = "bookmark.write"
+	ScopeMuteRead       = "mute.read"
+	ScopeMuteWrite      = "mute.write"
+	ScopeBlockRead      = "block.read"
+	ScopeBlockWrite     = "block.write"
+	ScopeOfflineAccess  = "offline.access"
+	ScopeSpaceRead      = "space.read"
+	ScopeTweetModerate  = "tweet.moderate.write"
+	ScopeDirectMsgRead  = "dm.read"
+	ScopeDirectMsgWrite = "dm.write"
+)
+
+// oauth2ExpiryDelta is how long before its expiry an access token is refreshed.
+const oauth2ExpiryDelta = time.Minute
+
+// An OAuth2Config describes a Twitter app using the OAuth 2.0 Authorization
+// Code with PKCE flow to obtain user context access tokens.
+type OAuth2Config struct {
+	// The client ID of the app.
+	ClientID string
+
+	// The client secret of confidential apps. Public apps leave it empty.
+	ClientSecret string
+
+	// The callback URL registered for the app.
+	RedirectURL string
+
+	// The scopes to request, for example ScopeTweetRead and ScopeOfflineAccess.
+	// A refresh token is only returned for the ScopeOfflineAccess scope.
+	Scopes []string
+
+	// The authorize URL users are sent to. Defaults to OAuth2AuthURL.
+	AuthURL string
+
+	// The token URL codes and refresh tokens are exchanged at. Defaults to
+	// OAuth2TokenURL.
+	TokenURL string
+
+	// The HTTP client to use when exchanging tokens. Defaults to
+	// `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// An OAuth2Token is an OAuth 2.0 user context access token.
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Scopes returns the scopes the access token was granted.
+func (t *OAuth2Token) Scopes() []string {
+	return strings.Fields(t.Scope)
+}
+
+// missingScopes returns the scopes which the access token was not granted.
+// Tokens without a scope are assumed to have been granted every scope.
+func (t *OAuth2Token) missingScopes(scopes []string) []string {
+	if t.Scope == "" {
+		return nil
+	}
+
+	granted := make(map[string]bool)
+	for _, s := range t.Scopes() {
+		granted[s] = true
+	}
+	var missing []string
+	for _, s := range scopes {
+		if !granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// expiresWithin checks if the access token expires within the duration.
+// Tokens without an expiry never expire.
+func (t *OAuth2Token) expiresWithin(d time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(d).After(t.Expiry)
+}
+
+// oauth2TokenResponse is the body returned by the token endpoint.
+type oauth2TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	Scope            string `json:"scope"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// An OAuth2Error is returned when the token endpoint rejects an exchange.
+type OAuth2Error struct {
+	// The status code of the HTTP response.
+	HTTPStatusCode int
+
+	// The OAuth 2.0 error code, for example "invalid_request".
+	Code string
+
+	// The description of the error.
+	Description string
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *OAuth2Error) Error() string {
+	extra := fmt.Sprintf("status code: %d", e.HTTPStatusCode)
+	return SprintError(fmt.Sprintf("OAuth2TokenError: %s: %s", e.Code, e.Description), extra, nil)
+}
+
+// StatusCode returns the status code of the HTTP response.
+func (e *OAuth2Error) StatusCode() int {
+	return e.HTTPStatusCode
+}
+
+// Message returns the description of the error.
+func (e *OAuth2Error) Message() string {
+	return e.Description
+}
+
+// A PKCE is a Proof Key for Code Exchange pair. The challenge is sent with
+// the authorize URL and the verifier with the code exchange.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE returns a new random PKCE pair using the S256 challenge method.
+func NewPKCE() (*PKCE, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		Method:    "S256",
+	}, nil
+}
+
+// AuthCodeURL returns the URL to send users to in order to authorize the app.
+// The state is returned to the redirect URL and should be checked by the
+// caller to prevent CSRF.
+func (c *OAuth2Config) AuthCodeURL(state string, pkce *PKCE) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.ClientID},
+		"redirect_uri":          {c.RedirectURL},
+		"scope":                 {strings.Join(c.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {pkce.Challenge},
+		"code_challenge_method": {pkce.Method},
+	}
+
+	authURL := c.AuthURL
+	if authURL == "" {
+		authURL = OAuth2AuthURL
+	}
+	if strings.Contains(authURL, "?") {
+		return authURL + "&" + params.Encode()
+	}
+	return authURL + "?" + params.Encode()
+}
+
+// Exchange exchanges the authorization code returned to the redirect URL for
+// an access token.
+func (c *OAuth2Config) Exchange(ctx context.Context, code string, pkce *PKCE) (*OAuth2Token, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"code_verifier": {pkce.Verifier},
+	})
+}
+
+// Refresh exchanges the refresh token for a new access token.
+func (c *OAuth2Config) Refresh(ctx context.Context, refreshToken string) (*OAuth2Token, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *OAuth2Config) retrieveToken(ctx context.Context, params url.Values) (*OAuth2Token, error) {
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = OAuth2TokenURL
+	}
+	// Public apps identify themselves in the body, confidential apps
+	// authenticate with their client secret instead.
+	if c.ClientSecret == "" {
+		params.Set("client_id", c.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, NewRequestFailure(err, 0, "failed to send OAuth2 token request")
+	}
+	defer resp.Body.Close()
+
+	var body oauth2TokenResponse
+	if err := UnmarshalJSON(&body, resp.Body); err != nil {
+		return nil, NewRequestFailure(err, resp.StatusCode, "Failed to decode OAuth2 token response")
+	}
+	if resp.StatusCode >= 400 || body.Error != "" || body.AccessToken == "" {
+		return nil, &OAuth2Error{
+			HTTPStatusCode: resp.StatusCode,
+			Code:           body.Error,
+			Description:    body.ErrorDescription,
+		}
+	}
+
+	token := &OAuth2Token{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		TokenType:    body.TokenType,
+		Scope:        body.Scope,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	// Refresh responses may omit the refresh token when it is not rotated.
+	if token.RefreshToken == "" {
+		token.RefreshToken = params.Get("refresh_token")
+	}
+	return token, nil
+}
@@ -0,0 +1,140 @@
+package twitter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func (suite *twitterClientSuite) newOAuth2Config() *OAuth2Config {
+	return &OAuth2Config{
+		ClientID:    "client-id",
+		RedirectURL: "https://example.com/callback",
+		Scopes:      []string{ScopeTweetRead, ScopeUsersRead, ScopeOfflineAccess},
+		AuthURL:     suite.server.URL + "/i/oauth2/authorize",
+		TokenURL:    suite.server.URL + "/2/oauth2/token",
+	}
+}
+
+func (suite *twitterClientSuite) Test_OAuth2AuthCodeURL() {
+	pkce, err := NewPKCE()
+	suite.Require().Nil(err)
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	suite.Assert().Equal(base64.RawURLEncoding.EncodeToString(sum[:]), pkce.Challenge)
+
+	authURL, err := url.Parse(suite.newOAuth2Config().AuthCodeURL("state", pkce))
+	suite.Require().Nil(err)
+
+	query := authURL.Query()
+	suite.Assert().Equal("/i/oauth2/authorize", authURL.Path)
+	suite.Assert().Equal("code", query.Get("response_type"))
+	suite.Assert().Equal("client-id", query.Get("client_id"))
+	suite.Assert().Equal("https://example.com/callback", query.Get("redirect_uri"))
+	suite.Assert().Equal("tweet.read users.read offline.access", query.Get("scope"))
+	suite.Assert().Equal("state", query.Get("state"))
+	suite.Assert().Equal(pkce.Challenge, query.Get("code_challenge"))
+	suite.Assert().Equal("S256", query.Get("code_challenge_method"))
+}
+
+func (suite *twitterClientSuite) Test_OAuth2Exchange() {
+	pkce, _ := NewPKCE()
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Nil(r.ParseForm())
+		suite.Assert().Equal("authorization_code", r.PostForm.Get("grant_type"))
+		suite.Assert().Equal("code", r.PostForm.Get("code"))
+		suite.Assert().Equal("client-id", r.PostForm.Get("client_id"))
+		suite.Assert().Equal(pkce.Verifier, r.PostForm.Get("code_verifier"))
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "access", "scope": "tweet.read users.read offline.access", "refresh_token": "refresh"}`)
+	})
+
+	token, err := suite.newOAuth2Config().Exchange(context.Background(), "code", pkce)
+
+	suite.Require().Nil(err)
+	suite.Assert().Equal("access", token.AccessToken)
+	suite.Assert().Equal("refresh", token.RefreshToken)
+	suite.Assert().Equal([]string{ScopeTweetRead, ScopeUsersRead, ScopeOfflineAccess}, token.Scopes())
+	suite.Assert().WithinDuration(time.Now().Add(2*time.Hour), token.Expiry, time.Minute)
+}
+
+func (suite *twitterClientSuite) Test_OAuth2ExchangeError() {
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "invalid_request", "error_description": "Value passed for the authorization code was invalid."}`)
+	})
+
+	pkce, _ := NewPKCE()
+	_, err := suite.newOAuth2Config().Exchange(context.Background(), "code", pkce)
+
+	var oauth2Err *OAuth2Error
+	suite.Require().True(errors.As(err, &oauth2Err))
+	suite.Assert().Equal("invalid_request", oauth2Err.Code)
+	suite.Assert().Equal(http.StatusBadRequest, oauth2Err.StatusCode())
+}
+
+func (suite *twitterClientSuite) Test_OAuth2RefreshBeforeExpiry() {
+	refreshes := 0
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		suite.Require().Nil(r.ParseForm())
+		suite.Assert().Equal("refresh_token", r.PostForm.Get("grant_type"))
+		suite.Assert().Equal("refresh", r.PostForm.Get("refresh_token"))
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "scope": "tweet.read users.read"}`)
+	})
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().Equal("Bearer fresh", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	creds := NewOAuth2Credentials(suite.newOAuth2Config(), &OAuth2Token{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(10 * time.Second),
+	})
+	suite.client.Config.WithCredentials(creds)
+
+	for i := 0; i < 2; i++ {
+		req := suite.newUserContextRequest()
+		suite.Assert().Nil(req.Send())
+	}
+
+	suite.Assert().Equal(1, refreshes)
+	value, _ := creds.Retrieve()
+	suite.Assert().Equal("fresh", value.OAuth2Token.AccessToken)
+	suite.Assert().Equal("refresh", value.OAuth2Token.RefreshToken)
+}
+
+func (suite *twitterClientSuite) Test_OAuth2RefreshOnUnauthorized() {
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "refresh_token": "rotated"}`)
+	})
+	attempts := 0
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"title": "Unauthorized", "type": "about:blank", "status": 401, "detail": "Unauthorized"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	creds := NewOAuth2Credentials(suite.newOAuth2Config(), &OAuth2Token{
+		AccessToken:  "revoked",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	})
+	suite.client.Config.WithCredentials(creds)
+
+	req := suite.newUserContextRequest()
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal(2, attempts)
+	value, _ := creds.Retrieve()
+	suite.Assert().Equal("rotated", value.OAuth2Token.RefreshToken)
+}
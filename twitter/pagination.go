@@ -0,0 +1,176 @@
+package twitter
+
+import (
+	"reflect"
+)
+
+// A PaginatedOutput is the output of an operation which returns its results
+// in pages, linked by a next page token.
+type PaginatedOutput interface {
+	// NextPageToken returns the token of the next page, or an empty string on
+	// the last page.
+	NextPageToken() string
+}
+
+// PaginationMeta contains the pagination information of a page.
+type PaginationMeta struct {
+	ResultCount   int    `json:"result_count,omitempty"`
+	NextToken     string `json:"next_token,omitempty"`
+	PreviousToken string `json:"previous_token,omitempty"`
+}
+
+// A Pagination provides paginating of operations which return their results in
+// pages, linked by the `meta.next_token` of a page and the `pagination_token`
+// of the request for the next page.
+//
+//	for p.NextPage() {
+//	    page := p.Page().(*twitter.GetRulesOutput)
+//	    // Use the page
+//	}
+//	return p.Err()
+//
+// See the GetRulesPages method for an example of an operation plugging in.
+type Pagination struct {
+	// Function to return a Request value for the page token. An empty token
+	// requests the first page.
+	NewRequest func(token string) (*Request, error)
+
+	// The page token to start at, for example the NextToken of a previous
+	// pagination to resume it.
+	Token string
+
+	// The maximum number of pages to request. Unlimited when zero.
+	MaxPages int
+
+	// The maximum number of items to iterate over. Pages are no longer
+	// requested once as many items were returned, and EachItem stops after
+	// the item reaching the limit. Unlimited when zero.
+	MaxItems int
+
+	started bool
+	err     error
+	curPage interface{}
+	pages   int
+	items   int
+}
+
+// HasNextPage will return true if Pagination is able to determine that the API
+// operation has additional pages, and the page and item limits allow to
+// request them. False will be returned if there are no more pages, or an error
+// was encountered.
+func (p *Pagination) HasNextPage() bool {
+	if p.err != nil {
+		return false
+	}
+	if p.MaxPages > 0 && p.pages >= p.MaxPages {
+		return false
+	}
+	if p.MaxItems > 0 && p.items >= p.MaxItems {
+		return false
+	}
+	return !p.started || p.Token != ""
+}
+
+// Err returns the error Pagination encountered when retrieving the next page.
+func (p *Pagination) Err() error {
+	return p.err
+}
+
+// Page returns the current page. Page should only be called after a successful
+// call to NextPage. It is undefined what Page will return if Page is called
+// after NextPage returns false.
+func (p *Pagination) Page() interface{} {
+	return p.curPage
+}
+
+// NextToken returns the token of the next page which was not requested yet,
+// or an empty string once the last page was requested. It can be stored to
+// resume the pagination later with the Token field.
+func (p *Pagination) NextToken() string {
+	return p.Token
+}
+
+// NextPage will attempt to retrieve the next page for the API operation. When
+// a page is retrieved true will be returned. If the page cannot be retrieved,
+// or there are no more pages false will be returned.
+//
+// Use the Page method to retrieve the current page data. The data will need
+// to be cast to the API operation's output type.
+//
+// Use the Err method to determine if an error occurred if NextPage returns
+// false.
+func (p *Pagination) NextPage() bool {
+	if !p.HasNextPage() {
+		return false
+	}
+
+	req, err := p.NewRequest(p.Token)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	if err := req.Send(); err != nil {
+		p.err = err
+		return false
+	}
+
+	p.started = true
+	p.pages++
+	p.curPage = req.Data
+	p.items += len(pageItems(req.Data))
+	p.Token = ""
+	if output, ok := req.Data.(PaginatedOutput); ok {
+		p.Token = output.NextPageToken()
+	}
+	return true
+}
+
+// EachPage calls fn with every page, until fn returns false or there are no
+// more pages. lastPage is true for the last page which will be requested.
+func (p *Pagination) EachPage(fn func(page interface{}, lastPage bool) bool) error {
+	for p.NextPage() {
+		if !fn(p.Page(), !p.HasNextPage()) {
+			break
+		}
+	}
+	return p.Err()
+}
+
+// EachItem calls fn with every item of every page, until fn returns false or
+// there are no more items. The items are the elements of the `Data` slice of
+// the pages, and will need to be cast to the element type, for example Rule
+// for GetRulesOutput pages.
+func (p *Pagination) EachItem(fn func(item interface{}) bool) error {
+	seen := p.items
+	for p.NextPage() {
+		for _, item := range pageItems(p.Page()) {
+			if p.MaxItems > 0 && seen >= p.MaxItems {
+				return nil
+			}
+			seen++
+			if !fn(item) {
+				return nil
+			}
+		}
+	}
+	return p.Err()
+}
+
+// pageItems returns the elements of the Data slice of the page, or nil if the
+// page has no Data slice.
+func pageItems(page interface{}) []interface{} {
+	v := reflect.Indirect(reflect.ValueOf(page))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	data := v.FieldByName("Data")
+	if !data.IsValid() || data.Kind() != reflect.Slice {
+		return nil
+	}
+
+	items := make([]interface{}, data.Len())
+	for i := range items {
+		items[i] = data.Index(i).Interface()
+	}
+	return items
+}
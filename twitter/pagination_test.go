@@ -0,0 +1,96 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// handleRulesPages serves three pages of two rules each, linked by next tokens.
+func (suite *twitterClientSuite) handleRulesPages() *[]string {
+	var tokens []string
+	pages := map[string]string{
+		"":      `{"data": [{"id": "1", "value": "a"}, {"id": "2", "value": "b"}], "meta": {"result_count": 2, "next_token": "page2"}}`,
+		"page2": `{"data": [{"id": "3", "value": "c"}, {"id": "4", "value": "d"}], "meta": {"result_count": 2, "next_token": "page3"}}`,
+		"page3": `{"data": [{"id": "5", "value": "e"}, {"id": "6", "value": "f"}], "meta": {"result_count": 2}}`,
+	}
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("pagination_token")
+		tokens = append(tokens, token)
+		suite.Assert().Equal("2", r.URL.Query().Get("max_results"))
+		fmt.Fprint(w, pages[token])
+	})
+	return &tokens
+}
+
+func (suite *twitterClientSuite) Test_GetRulesPages() {
+	tokens := suite.handleRulesPages()
+
+	var ids []string
+	var lastPages []bool
+	err := suite.client.GetRulesPages(&GetRulesInput{MaxResults: 2}, func(page *GetRulesOutput, lastPage bool) bool {
+		for _, rule := range page.Data {
+			ids = append(ids, rule.ID.String())
+		}
+		lastPages = append(lastPages, lastPage)
+		return true
+	})
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal([]string{"1", "2", "3", "4", "5", "6"}, ids)
+	suite.Assert().Equal([]bool{false, false, true}, lastPages)
+	suite.Assert().Equal([]string{"", "page2", "page3"}, *tokens)
+}
+
+func (suite *twitterClientSuite) Test_PaginationMaxPagesAndResume() {
+	tokens := suite.handleRulesPages()
+
+	p := suite.client.GetRulesPagination(context.Background(), &GetRulesInput{MaxResults: 2})
+	p.MaxPages = 2
+	pages := 0
+	for p.NextPage() {
+		pages++
+	}
+	suite.Assert().Nil(p.Err())
+	suite.Assert().Equal(2, pages)
+	suite.Assert().Equal("page3", p.NextToken())
+
+	resumed := suite.client.GetRulesPagination(context.Background(), &GetRulesInput{MaxResults: 2, PaginationToken: p.NextToken()})
+	suite.Require().True(resumed.NextPage())
+	suite.Assert().Equal("5", resumed.Page().(*GetRulesOutput).Data[0].ID.String())
+	suite.Assert().False(resumed.HasNextPage())
+	suite.Assert().False(resumed.NextPage())
+	suite.Assert().Equal([]string{"", "page2", "page3"}, *tokens)
+}
+
+func (suite *twitterClientSuite) Test_PaginationEachItemMaxItems() {
+	tokens := suite.handleRulesPages()
+
+	p := suite.client.GetRulesPagination(context.Background(), &GetRulesInput{MaxResults: 2})
+	p.MaxItems = 3
+	var values []string
+	err := p.EachItem(func(item interface{}) bool {
+		values = append(values, item.(Rule).Value)
+		return true
+	})
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal([]string{"a", "b", "c"}, values)
+	suite.Assert().Equal([]string{"", "page2"}, *tokens)
+}
+
+func (suite *twitterClientSuite) Test_PaginationError() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"title": "Invalid Request", "detail": "One or more parameters to your request was invalid.", "type": "https://api.twitter.com/2/problems/invalid-request"}`)
+	})
+
+	calls := 0
+	err := suite.client.GetRulesPages(&GetRulesInput{}, func(*GetRulesOutput, bool) bool {
+		calls++
+		return true
+	})
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(0, calls)
+}
@@ -0,0 +1,121 @@
+package twitter
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Twitter API rate limit response headers
+const (
+	rateLimitLimitHeader     = "x-rate-limit-limit"
+	rateLimitRemainingHeader = "x-rate-limit-remaining"
+	rateLimitResetHeader     = "x-rate-limit-reset"
+)
+
+// rateLimitResetPadding is added to the reset time when waiting for a rate
+// limit window to reset, to make up for clock skew with Twitter API servers.
+var rateLimitResetPadding = time.Second
+
+// A RateLimit contains the rate limit status of an endpoint as reported by
+// the Twitter API response headers.
+type RateLimit struct {
+	// The maximum number of requests allowed in the current window.
+	Limit int
+
+	// The number of requests left in the current window.
+	Remaining int
+
+	// The time the current window resets.
+	Reset time.Time
+}
+
+// UntilReset returns the duration left until the rate limit window resets.
+func (r RateLimit) UntilReset() time.Duration {
+	return time.Until(r.Reset)
+}
+
+// parseRateLimit returns the rate limit reported in the response headers, or
+// nil if the headers are missing or malformed.
+func parseRateLimit(header http.Header) *RateLimit {
+	limit, err := strconv.Atoi(header.Get(rateLimitLimitHeader))
+	if err != nil {
+		return nil
+	}
+	remaining, err := strconv.Atoi(header.Get(rateLimitRemainingHeader))
+	if err != nil {
+		return nil
+	}
+	reset, err := strconv.ParseInt(header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}
+}
+
+// A RateLimitTracker keeps the last rate limit reported for each endpoint,
+// keyed by EndPointInfo.Name. It is safe for concurrent use.
+type RateLimitTracker struct {
+	mu     sync.RWMutex
+	limits map[string]RateLimit
+}
+
+// NewRateLimitTracker returns an empty RateLimitTracker.
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{
+		limits: make(map[string]RateLimit),
+	}
+}
+
+// Get returns the last rate limit reported for the endpoint name, and false if
+// no request was sent to the endpoint yet.
+func (t *RateLimitTracker) Get(name string) (RateLimit, bool) {
+	if t == nil {
+		return RateLimit{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	limit, ok := t.limits[name]
+	return limit, ok
+}
+
+// Set records the rate limit reported for the endpoint name.
+func (t *RateLimitTracker) Set(name string, limit RateLimit) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits[name] = limit
+}
+
+// RateLimitRecorder is a request handler which reads the rate limit headers
+// off the request's response and reports them to the client's tracker.
+var RateLimitRecorder = HandlerFunction{
+	Name: "RateLimitRecorder",
+	Fn: func(r *Request) {
+		r.RateLimit = parseRateLimit(r.HTTPResponse.Header)
+		if r.RateLimit != nil {
+			r.rateLimits.Set(r.EndPointInfo.Name, *r.RateLimit)
+		}
+	},
+}
+
+// rateLimitDelay returns how long a rate limited request has to wait before
+// the rate limit window resets, and false if the reset time is unknown.
+func rateLimitDelay(r *Request) (time.Duration, bool) {
+	if r.RateLimit == nil {
+		return 0, false
+	}
+	delay := r.RateLimit.UntilReset() + rateLimitResetPadding
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
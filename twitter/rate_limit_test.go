@@ -0,0 +1,80 @@
+package twitter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_RateLimitHeaders() {
+	reset := time.Now().Add(15 * time.Minute).Unix()
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-rate-limit-limit", "450")
+		w.Header().Set("x-rate-limit-remaining", "449")
+		w.Header().Set("x-rate-limit-reset", strconv.FormatInt(reset, 10))
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Require().NotNil(req.RateLimit)
+	suite.Assert().Equal(450, req.RateLimit.Limit)
+	suite.Assert().Equal(449, req.RateLimit.Remaining)
+	suite.Assert().Equal(reset, req.RateLimit.Reset.Unix())
+
+	limit, ok := suite.client.RateLimit(getRules)
+	suite.Assert().True(ok)
+	suite.Assert().Equal(*req.RateLimit, limit)
+}
+
+// constantRetryer is a Retryer which retries every failed request after the
+// same delay.
+type constantRetryer struct {
+	delay   time.Duration
+	retries int
+}
+
+func (c constantRetryer) RetryRules(*Request) time.Duration { return c.delay }
+func (c constantRetryer) ShouldRetry(*Request) bool         { return true }
+func (c constantRetryer) MaxRetries() int                   { return c.retries }
+
+func (suite *twitterClientSuite) Test_WaitOnRateLimit() {
+	defer func(padding time.Duration) { rateLimitResetPadding = padding }(rateLimitResetPadding)
+	rateLimitResetPadding = 0
+
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("x-rate-limit-limit", "450")
+		w.Header().Set("x-rate-limit-reset", strconv.FormatInt(time.Now().Unix(), 10))
+		if attempts%2 == 1 {
+			w.Header().Set("x-rate-limit-remaining", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintf(w, `{"title": "Too Many Requests", "detail": "Too Many Requests", "type": "about:blank"}`)
+			return
+		}
+		w.Header().Set("x-rate-limit-remaining", "449")
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	suite.client.Config.WithWaitOnRateLimit(true)
+	// The reset is waited for instead of the retryer's delay, whichever the
+	// retryer is.
+	for _, retryer := range []Retryer{
+		DefaultRetryer{NumMaxRetries: 1, MinThrottleDelay: time.Hour, MaxThrottleDelay: time.Hour},
+		constantRetryer{delay: time.Hour, retries: 1},
+	} {
+		attempts = 0
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		req.Retryer = retryer
+		err := req.Send()
+
+		suite.Assert().Nil(err, "%T", retryer)
+		suite.Assert().Equal(2, attempts, "%T", retryer)
+		suite.Assert().True(req.RetryDelay <= time.Second, "%T", retryer)
+		suite.Assert().Equal(449, req.RateLimit.Remaining, "%T", retryer)
+	}
+}
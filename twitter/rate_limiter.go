@@ -0,0 +1,267 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ClientRateLimitPolicy sets how requests behave when the client-side rate
+// limiter has no budget left for their endpoint.
+type ClientRateLimitPolicy int8
+
+const (
+	// ClientRateLimitDisabled sends requests without client-side limiting.
+	ClientRateLimitDisabled ClientRateLimitPolicy = iota
+
+	// ClientRateLimitBlock makes requests wait until the limiter has budget
+	// for them, or their context is done.
+	ClientRateLimitBlock
+
+	// ClientRateLimitFailFast makes requests fail with a
+	// *ClientRateLimitError without being sent.
+	ClientRateLimitFailFast
+)
+
+// A Quota is a documented rate limit window of an endpoint.
+type Quota struct {
+	// The number of requests allowed per window.
+	Limit int
+
+	// The length of the window.
+	Window time.Duration
+
+	// PerUser makes the quota apply to each user the app acts on behalf of,
+	// instead of to the app as a whole.
+	PerUser bool
+
+	// Bucket names the quota's token bucket, so endpoints whose quotas have
+	// the same Bucket share their tokens, for example operations sent to the
+	// same HTTP endpoint. Defaults to the endpoint name.
+	Bucket string
+}
+
+// Names of write endpoints the client has no operations for. Requests created
+// with NewRequest and an EndPointInfo of these names are limited by their
+// DefaultEndpointQuotas.
+const (
+	CreateTweetEndpointName = "createTweet"
+	LikeTweetEndpointName   = "likeTweet"
+	FollowUserEndpointName  = "followUser"
+)
+
+// rulesQuota is the quota shared by the operations which post to the stream
+// rules endpoint.
+var rulesQuota = Quota{Limit: 450, Window: 15 * time.Minute, Bucket: "POST tweets/search/stream/rules"}
+
+// DefaultEndpointQuotas are the documented rate limits of endpoints, keyed by
+// EndPointInfo.Name. Operations with these names are limited by the client
+// rate limiter, unless their quotas are overridden by Config.EndpointQuotas.
+// The quota of a stream endpoint limits its connection attempts, reconnects
+// included.
+var DefaultEndpointQuotas = map[string][]Quota{
+	validateRules: {rulesQuota},
+	createRules:   {rulesQuota},
+	deleteRules:   {rulesQuota},
+	getRules:      {{Limit: 450, Window: 15 * time.Minute}},
+	streamTweets:  {{Limit: 50, Window: 15 * time.Minute}},
+	CreateTweetEndpointName: {
+		{Limit: 200, Window: 15 * time.Minute, PerUser: true},
+		{Limit: 300, Window: 3 * time.Hour, PerUser: true},
+	},
+	LikeTweetEndpointName: {
+		{Limit: 50, Window: 15 * time.Minute, PerUser: true},
+		{Limit: 1000, Window: 24 * time.Hour, PerUser: true},
+	},
+	FollowUserEndpointName: {
+		{Limit: 50, Window: 15 * time.Minute, PerUser: true},
+		{Limit: 400, Window: 24 * time.Hour, PerUser: true},
+	},
+}
+
+// A Clock tells the time and waits for durations to pass. It can be replaced
+// in tests to control the time seen by the rate limiter.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock of the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// A ClientRateLimitError is returned by requests the client-side rate limiter
+// had no budget for, when using the ClientRateLimitFailFast policy.
+type ClientRateLimitError struct {
+	// The name of the endpoint.
+	EndPointName string
+
+	// How long until the limiter has budget for the request.
+	RetryAfter time.Duration
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *ClientRateLimitError) Error() string {
+	return fmt.Sprintf("ClientRateLimited: no budget left for %s, retry after %s", e.EndPointName, e.RetryAfter)
+}
+
+// A RateLimiter is a client-side token bucket rate limiter, keyed by endpoint
+// name, which keeps requests within the documented quotas of their endpoints.
+// Every quota is a bucket holding up to Limit tokens, refilled evenly over its
+// window. A request takes a token from every bucket of its endpoint.
+//
+// A RateLimiter is safe to use concurrently.
+type RateLimiter struct {
+	quotas map[string][]Quota
+	policy ClientRateLimitPolicy
+	clock  Clock
+
+	m       sync.Mutex
+	buckets map[bucketKey]*tokenBucket
+}
+
+type bucketKey struct {
+	endpoint string
+	quota    int
+	user     string
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a pointer to a new RateLimiter with the
+// DefaultEndpointQuotas, overridden by the quotas. If clock is nil the system
+// time is used.
+func NewRateLimiter(quotas map[string][]Quota, policy ClientRateLimitPolicy, clock Clock) *RateLimiter {
+	merged := make(map[string][]Quota, len(DefaultEndpointQuotas)+len(quotas))
+	for name, q := range DefaultEndpointQuotas {
+		merged[name] = q
+	}
+	for name, q := range quotas {
+		merged[name] = q
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return &RateLimiter{
+		quotas:  merged,
+		policy:  policy,
+		clock:   clock,
+		buckets: make(map[bucketKey]*tokenBucket),
+	}
+}
+
+// Wait takes a token for a request to the endpoint on behalf of the user,
+// which is empty for the user of the configured credentials. Depending on
+// the policy it waits until tokens are available or the context is done, or
+// fails with a *ClientRateLimitError right away.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint, userID string) error {
+	if l == nil || l.policy == ClientRateLimitDisabled {
+		return nil
+	}
+
+	for {
+		wait := l.take(endpoint, userID)
+		if wait == 0 {
+			return nil
+		}
+		if l.policy == ClientRateLimitFailFast {
+			return &ClientRateLimitError{EndPointName: endpoint, RetryAfter: wait}
+		}
+
+		select {
+		case <-l.clock.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// take takes a token from every bucket of the endpoint and returns zero, or
+// returns how long until every bucket has a token without taking any.
+func (l *RateLimiter) take(endpoint, userID string) time.Duration {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	quotas := l.quotas[endpoint]
+	now := l.clock.Now()
+	buckets := make([]*tokenBucket, len(quotas))
+	var wait time.Duration
+	for i, q := range quotas {
+		key := bucketKey{endpoint: endpoint, quota: i}
+		if q.Bucket != "" {
+			key.endpoint = q.Bucket
+		}
+		if q.PerUser {
+			key.user = userID
+		}
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &tokenBucket{tokens: float64(q.Limit), last: now}
+			l.buckets[key] = b
+		}
+
+		// Refill the tokens which accrued since the bucket was last used.
+		rate := float64(q.Limit) / float64(q.Window)
+		b.tokens = math.Min(float64(q.Limit), b.tokens+rate*float64(now.Sub(b.last)))
+		b.last = now
+		buckets[i] = b
+
+		if b.tokens < 1 {
+			if w := time.Duration(math.Ceil((1 - b.tokens) / rate)); w > wait {
+				wait = w
+			}
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// ClientRateLimitHandler is a request handler which waits for the client-side
+// rate limiter to have budget for the request before it is signed and sent.
+var ClientRateLimitHandler = HandlerFunction{
+	Name: "ClientRateLimitHandler",
+	Fn: func(r *Request) {
+		if r.EndPointInfo == nil {
+			return
+		}
+		r.Error = r.rateLimiter.Wait(r.Context(), r.EndPointInfo.Name, r.UserID)
+	},
+}
+
+// StreamClientRateLimitHandler is a stream handler which waits for the
+// client-side rate limiter to have budget for a connection attempt before
+// the stream request is signed. Waiting ends once the stream is stopped.
+var StreamClientRateLimitHandler = StreamHandlerFunction{
+	Name: "ClientRateLimitHandler",
+	Fn: func(s *Stream) {
+		if s.EndPointInfo == nil {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(s.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-s.done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		s.Error = s.rateLimiter.Wait(ctx, s.EndPointInfo.Name, s.UserID)
+	},
+}
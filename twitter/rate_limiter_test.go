@@ -0,0 +1,181 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock whose time only moves when waited on.
+type fakeClock struct {
+	m      sync.Mutex
+	now    time.Time
+	waited time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.now = c.now.Add(d)
+	c.waited += d
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1640995200, 0)}
+	l := NewRateLimiter(map[string][]Quota{
+		"createTweet": {{Limit: 2, Window: time.Minute, PerUser: true}},
+	}, ClientRateLimitBlock, clock)
+	ctx := context.Background()
+
+	assert.Nil(t, l.Wait(ctx, "createTweet", "2244994945"))
+	assert.Nil(t, l.Wait(ctx, "createTweet", "2244994945"))
+	assert.Equal(t, time.Duration(0), clock.waited)
+
+	// Another user has a bucket of its own.
+	assert.Nil(t, l.Wait(ctx, "createTweet", "783214"))
+	assert.Equal(t, time.Duration(0), clock.waited)
+
+	// One token is refilled every 30 seconds.
+	assert.Nil(t, l.Wait(ctx, "createTweet", "2244994945"))
+	assert.Equal(t, 30*time.Second, clock.waited)
+
+	// Endpoints without quotas are not limited.
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, l.Wait(ctx, "unknown", ""))
+	}
+	assert.Equal(t, 30*time.Second, clock.waited)
+}
+
+func TestRateLimiterMultipleWindows(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1640995200, 0)}
+	l := NewRateLimiter(map[string][]Quota{
+		"likeTweet": {
+			{Limit: 2, Window: time.Minute, PerUser: true},
+			{Limit: 3, Window: time.Hour, PerUser: true},
+		},
+	}, ClientRateLimitFailFast, clock)
+	ctx := context.Background()
+
+	assert.Nil(t, l.Wait(ctx, "likeTweet", ""))
+	assert.Nil(t, l.Wait(ctx, "likeTweet", ""))
+
+	err := l.Wait(ctx, "likeTweet", "")
+	delay, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.Nil(t, l.Wait(ctx, "likeTweet", ""))
+
+	// The minute window is refilled, but the hourly one is used up.
+	clock.now = clock.now.Add(time.Minute)
+	err = l.Wait(ctx, "likeTweet", "")
+	delay, ok = RetryAfter(err)
+	assert.True(t, ok)
+	assert.True(t, delay > 15*time.Minute && delay <= 20*time.Minute, "delay %s", delay)
+}
+
+func TestRateLimiterDefaultQuotas(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1640995200, 0)}
+	l := NewRateLimiter(nil, ClientRateLimitFailFast, clock)
+	ctx := context.Background()
+
+	// The operations posting to the rules endpoint share its quota.
+	for i := 0; i < 450; i++ {
+		endpoint := []string{validateRules, createRules, deleteRules}[i%3]
+		assert.Nil(t, l.Wait(ctx, endpoint, ""), endpoint)
+	}
+	for _, endpoint := range []string{validateRules, createRules, deleteRules} {
+		assert.True(t, IsRateLimited(l.Wait(ctx, endpoint, "")), endpoint)
+	}
+	assert.Nil(t, l.Wait(ctx, getRules, ""))
+
+	// Write endpoints are limited per user.
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, l.Wait(ctx, LikeTweetEndpointName, "2244994945"))
+	}
+	assert.True(t, IsRateLimited(l.Wait(ctx, LikeTweetEndpointName, "2244994945")))
+	assert.Nil(t, l.Wait(ctx, LikeTweetEndpointName, "783214"))
+}
+
+func TestRateLimiterContextCanceled(t *testing.T) {
+	l := NewRateLimiter(map[string][]Quota{
+		getRules: {{Limit: 1, Window: time.Hour}},
+	}, ClientRateLimitBlock, nil)
+	assert.Nil(t, l.Wait(context.Background(), getRules, ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx, getRules, ""))
+}
+
+func (suite *twitterClientSuite) Test_ClientRateLimitFailFast() {
+	requests := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+	clock := &fakeClock{now: time.Now()}
+	suite.client = NewClient(suite.client.Config.
+		WithClientRateLimitPolicy(ClientRateLimitFailFast).
+		WithEndpointQuotas(map[string][]Quota{getRules: {{Limit: 2, Window: 15 * time.Minute}}}).
+		WithClock(clock))
+
+	for i := 0; i < 2; i++ {
+		req, _ := suite.client.GetRules(&GetRulesInput{})
+		suite.Assert().Nil(req.Send())
+	}
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	var limitErr *ClientRateLimitError
+	suite.Require().ErrorAs(err, &limitErr)
+	suite.Assert().Equal(getRules, limitErr.EndPointName)
+	suite.Assert().Equal(2, requests)
+	suite.Assert().True(IsRateLimited(err))
+	suite.Assert().True(IsRetryable(err))
+}
+
+func (suite *twitterClientSuite) Test_StreamClientRateLimitFailFast() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		// Drop the connection after every message.
+		fmt.Fprintf(w, `{"data": {"id": "%d", "text": "Twitter API is awesome!"}}`+"\r\n", attempts)
+	})
+	suite.client = NewClient(suite.client.Config.
+		WithClientRateLimitPolicy(ClientRateLimitFailFast).
+		WithEndpointQuotas(map[string][]Quota{streamTweets: {{Limit: 2, Window: 15 * time.Minute}}}).
+		WithClock(&fakeClock{now: time.Now()}))
+	suite.client.StreamRetryer = newTestStreamRetryer(5)
+
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	defer stream.Stop()
+
+	// The reconnect after the second connection is out of budget.
+	messages := 0
+	for range stream.MessageQueue {
+		messages++
+	}
+
+	var limitErr *ClientRateLimitError
+	suite.Require().ErrorAs(stream.Err(), &limitErr)
+	suite.Assert().Equal(streamTweets, limitErr.EndPointName)
+	suite.Assert().Equal(2, messages)
+	suite.Assert().Equal(2, attempts)
+}
@@ -1,9 +1,12 @@
 package twitter
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -21,12 +24,45 @@ type Request struct {
 	Time         time.Time
 	HTTPRequest  *http.Request
 	HTTPResponse *http.Response
+	RateLimit    *RateLimit
 	Retryable    *bool
+	RetryCount   int
+	RetryDelay   time.Duration
 	PayLoad      interface{}
 	Error        error
 	Data         interface{}
 	Handlers     Handlers
 	Retryer
+
+	// UserID is the ID of the user the request acts on behalf of. The
+	// request is signed with the user's credentials from the config's
+	// credential store.
+	UserID string
+
+	context              context.Context
+	rateLimits           *RateLimitTracker
+	rateLimiter          *RateLimiter
+	refreshedCredentials bool
+}
+
+// An Option is a functional option that can augment or modify a request when
+// using an operation method, such as GetRules.
+type Option func(*Request)
+
+// WithRequestCredentials is a request option which signs the request with the
+// credentials instead of the config's credentials.
+func WithRequestCredentials(creds *Credentials) Option {
+	return func(r *Request) {
+		r.Config.Credentials = creds
+	}
+}
+
+// WithUserID is a request option which signs the request on behalf of the
+// user, with the credentials looked up in the config's credential store.
+func WithUserID(userID string) Option {
+	return func(r *Request) {
+		r.UserID = userID
+	}
 }
 
 // An EndPointInfo is the endpoint info to create the request.
@@ -35,6 +71,30 @@ type EndPointInfo struct {
 	HTTPMethod  string
 	HTTPPath    string
 	QueryParams map[string]string
+
+	// Endpoint overrides the client's base endpoint for this endpoint, for
+	// example to reach the upload host.
+	Endpoint string
+
+	// APIVersion overrides the client's API version for this endpoint, for
+	// example "1.1" to reach a v1.1 endpoint.
+	APIVersion string
+
+	// Idempotent marks whether sending the request more than once has the
+	// same effect as sending it once. Retryers only replay requests which
+	// are not idempotent when they were rejected before being processed.
+	// When nil, GET, HEAD, PUT, DELETE and OPTIONS are idempotent.
+	Idempotent *bool
+
+	// AuthTypes lists the auth types the endpoint supports, in order of
+	// preference. The first one the credentials hold signs the request, and
+	// the request fails with an AuthError before being sent if there is none.
+	// When empty, any credentials are used.
+	AuthTypes []AuthType
+
+	// Scopes lists the OAuth 2.0 scopes a user context access token must have
+	// been granted to call the endpoint.
+	Scopes []string
 }
 
 // Validate checks if an endpoint struct has required fields before being used in sending a request
@@ -45,6 +105,54 @@ func (e *EndPointInfo) Validate() error {
 	return nil
 }
 
+// IsIdempotent returns whether the endpoint can safely be called more than
+// once for the same request.
+func (e *EndPointInfo) IsIdempotent() bool {
+	if e == nil {
+		return false
+	}
+	if e.Idempotent != nil {
+		return *e.Idempotent
+	}
+	switch e.HTTPMethod {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// buildURL returns the URL of the endpoint with its query parameters. The
+// endpoint's base endpoint and API version overrides take precedence over the
+// client's API information.
+func (e *EndPointInfo) buildURL(apiInfo APIInfo) (*url.URL, error) {
+	endpoint, apiVersion := apiInfo.Endpoint, apiInfo.APIVersion
+	if e.Endpoint != "" {
+		var err error
+		if endpoint, err = parseEndpoint(e.Endpoint); err != nil {
+			return nil, err
+		}
+	} else if apiInfo.err != nil {
+		return nil, apiInfo.err
+	}
+	if e.APIVersion != "" {
+		apiVersion = e.APIVersion
+	}
+
+	u, err := url.Parse(strings.TrimRight(endpoint, "/") + "/" + apiVersion + "/" + e.HTTPPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.QueryParams != nil {
+		q := u.Query()
+		for k, v := range e.QueryParams {
+			q.Add(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u, nil
+}
+
 func (e *EndPointInfo) String() string {
 	return fmt.Sprintf("name: %s, method: %s, path: %s", e.Name, e.HTTPMethod, e.HTTPPath)
 }
@@ -52,12 +160,19 @@ func (e *EndPointInfo) String() string {
 // NewRequest returns a new Request pointer for the service API
 // operation and parameters.
 func (c *Client) NewRequest(endpoint *EndPointInfo, input, output interface{}) *Request {
-	return CreateRequest(*c.Config, c.APIInfo, c.Retryer, endpoint, input, output)
+	r := CreateRequest(*c.Config, c.APIInfo, c.Handlers, c.Retryer, endpoint, input, output)
+	r.rateLimits = c.RateLimits
+	r.rateLimiter = c.RateLimiter
+	return r
 }
 
 // CreateRequest returns a new Request pointer for the service API
 // operation and parameters.
 //
+// The handler lists are copied, so handlers added to the request do not
+// affect other requests. If every list is empty, the request is created with
+// the DefaultHandlers.
+//
 // A Retryer should be provided to direct how the request is retried. If
 // Retryer is nil, a default no retry value will be used. You can use
 // NoOpRetryer in the Client package to disable retry behavior directly.
@@ -65,13 +180,16 @@ func (c *Client) NewRequest(endpoint *EndPointInfo, input, output interface{}) *
 // Params is any value of input parameters to be the request payload.
 // Data is pointer value to an object which the request's response
 // payload will be deserialized to.
-func CreateRequest(cfg Config, apiInfo APIInfo,
+func CreateRequest(cfg Config, apiInfo APIInfo, handlers Handlers,
 	retryer Retryer, endpointInfo *EndPointInfo, payLoad interface{}, data interface{}) *Request {
 	var err error
 
 	if retryer == nil {
 		retryer = noRetryer{}
 	}
+	if handlers.isEmpty() {
+		handlers = DefaultHandlers()
+	}
 
 	if err = endpointInfo.Validate(); err != nil {
 		return &Request{Error: err}
@@ -84,33 +202,18 @@ func CreateRequest(cfg Config, apiInfo APIInfo,
 
 	httpReq.Header.Add("Content-type", "application/json")
 
-	httpReq.URL, err = url.Parse(apiInfo.Endpoint + "/" + apiInfo.APIVersion + "/" + endpointInfo.HTTPPath)
+	httpReq.URL, err = endpointInfo.buildURL(apiInfo)
 	if err != nil {
 		httpReq.URL = &url.URL{}
 		return &Request{Error: err}
 	}
 
-	if endpointInfo.QueryParams != nil {
-		q := httpReq.URL.Query()
-		for k, v := range endpointInfo.QueryParams {
-			q.Add(k, v)
-		}
-		httpReq.URL.RawQuery = q.Encode()
-	}
-
 	if payLoad != nil {
 		b, err := json.Marshal(payLoad)
 		if err != nil {
 			return &Request{Error: err}
 		}
-		httpReq.Body = ioutil.NopCloser(strings.NewReader(string(b)))
-	}
-
-	handlers := Handlers{
-		Send:           SendHandler,
-		Unmarshal:      UnMarshaler,
-		ErrorUnmarshal: ErrorUnmarshaler,
-		Sign:           Signer,
+		setRequestBody(httpReq, b)
 	}
 
 	r := &Request{
@@ -131,6 +234,46 @@ func CreateRequest(cfg Config, apiInfo APIInfo,
 
 }
 
+// ApplyOptions will apply each option to the request calling them in the
+// order they were provided.
+func (r *Request) ApplyOptions(opts ...Option) {
+	for _, opt := range opts {
+		opt(r)
+	}
+}
+
+// Context returns the context set on the request, or a background context if
+// none was set.
+func (r *Request) Context() context.Context {
+	if r.context != nil {
+		return r.context
+	}
+	return backgroundCtx
+}
+
+// SetContext adds a Context to the current request that can be used to cancel
+// an in-flight request and abort the retry delays between attempts. The
+// context is attached to the HTTPRequest as well.
+//
+// Panics if a nil context is passed in.
+func (r *Request) SetContext(ctx context.Context) {
+	if ctx == nil {
+		panic("context cannot be nil")
+	}
+	r.context = ctx
+	if r.HTTPRequest != nil {
+		r.HTTPRequest = r.HTTPRequest.WithContext(ctx)
+	}
+}
+
+// SendWithContext sets the context on the request and sends it, returning
+// error if errors are encountered or the context is done before the request
+// completes.
+func (r *Request) SendWithContext(ctx context.Context) error {
+	r.SetContext(ctx)
+	return r.Send()
+}
+
 // Send will send the request, returning error if errors are encountered.
 func (r *Request) Send() error {
 	if err := r.Error; err != nil {
@@ -141,6 +284,10 @@ func (r *Request) Send() error {
 		r.Error = nil
 		r.AttemptTime = time.Now()
 
+		if err := r.prepareAttempt(); err != nil {
+			return err
+		}
+
 		if err := r.Sign(); err != nil {
 			return err
 		}
@@ -158,6 +305,23 @@ func (r *Request) Send() error {
 	}
 }
 
+// prepareAttempt gives every attempt its own HTTP request with a fresh body,
+// since the previous attempt's body may already have been consumed.
+func (r *Request) prepareAttempt() error {
+	r.HTTPRequest = r.HTTPRequest.Clone(r.Context())
+	if r.HTTPRequest.GetBody == nil {
+		return nil
+	}
+
+	body, err := r.HTTPRequest.GetBody()
+	if err != nil {
+		r.Error = NewRequestFailure(err, 0, "failed to rewind request body")
+		return r.Error
+	}
+	r.HTTPRequest.Body = body
+	return nil
+}
+
 // Sign will sign the request, returning error if errors are encountered.
 func (r *Request) Sign() error {
 	if r.Error != nil {
@@ -167,6 +331,22 @@ func (r *Request) Sign() error {
 	return r.Error
 }
 
+// retrieveCredentials retrieves the credentials to sign the request with.
+// The credentials of the user the request acts on behalf of are looked up
+// again for every attempt, and kept on the request's config so they can be
+// refreshed after the attempt.
+func (r *Request) retrieveCredentials() (Value, error) {
+	creds, err := resolveCredentials(r.Context(), r.Config, r.UserID)
+	if err != nil {
+		return Value{}, err
+	}
+	if creds == nil {
+		return Value{}, ErrCredentialsEmpty
+	}
+	r.Config.Credentials = creds
+	return creds.RetrieveWithContext(r.Context())
+}
+
 func (r *Request) sendRequest() (sendErr error) {
 	r.Retryable = nil
 	r.Handlers.Send.Run(r)
@@ -190,6 +370,21 @@ func (r *Request) sendRequest() (sendErr error) {
 	return nil
 }
 
+// setRequestBody sets the payload as the HTTP request's body, along with a
+// GetBody function so the body can be read again for every attempt.
+func setRequestBody(req *http.Request, b []byte) {
+	req.ContentLength = int64(len(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+}
+
+// WillRetry returns if the request's can be retried.
+func (r *Request) WillRetry() bool {
+	return r.Error != nil && BoolValue(r.Retryable) && r.RetryCount < r.MaxRetries()
+}
+
 func debugLogReqError(r *Request, stage string, err error) {
 	r.Config.Logger.Debug().Str("stage", stage).Msg(err.Error())
 }
@@ -2,20 +2,44 @@ package twitter
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 )
 
-// A Handlers provides a collection of request handlers for various
+// A Handlers provides a collection of request handler lists for various
 // stages of handling requests.
 type Handlers struct {
-	Sign           HandlerFunction
-	Send           HandlerFunction
-	Unmarshal      HandlerFunction
-	ErrorUnmarshal HandlerFunction
-	Retry          HandlerFunction
-	AfterRetry     HandlerFunction
+	Sign           HandlerList
+	Send           HandlerList
+	Unmarshal      HandlerList
+	ErrorUnmarshal HandlerList
+	Retry          HandlerList
+	AfterRetry     HandlerList
+}
+
+// DefaultHandlers returns the handler lists requests are created with. The
+// Sign, Send, ErrorUnmarshal and Unmarshal lists stop at the first handler
+// which sets the request's error.
+func DefaultHandlers() Handlers {
+	var handlers Handlers
+
+	handlers.Sign.PushBackNamed(ClientRateLimitHandler)
+	handlers.Sign.PushBackNamed(Signer)
+	handlers.Send.PushBackNamed(SendHandler)
+	handlers.Send.PushBackNamed(RateLimitRecorder)
+	handlers.ErrorUnmarshal.PushBackNamed(ErrorUnmarshaler)
+	handlers.Unmarshal.PushBackNamed(UnMarshaler)
+	handlers.Unmarshal.PushBackNamed(PartialErrorHandler)
+	handlers.Retry.PushBackNamed(RefreshCredentialsHandler)
+	handlers.Retry.PushBackNamed(RetryHandler)
+	handlers.AfterRetry.PushBackNamed(AfterRetryHandler)
+
+	handlers.Sign.AfterEachFn = HandlerListStopOnError
+	handlers.Send.AfterEachFn = HandlerListStopOnError
+	handlers.ErrorUnmarshal.AfterEachFn = HandlerListStopOnError
+	handlers.Unmarshal.AfterEachFn = HandlerListStopOnError
+
+	return handlers
 }
 
 // Copy returns a copy of this handler's lists.
@@ -30,20 +54,159 @@ func (h *Handlers) Copy() Handlers {
 	}
 }
 
-// A HandlerFunction is a struct that contains a name and function callback.
-type HandlerFunction struct {
-	Name string
-	Fn   func(*Request)
+// isEmpty returns true if none of the handler lists has a handler, as for
+// the zero value of a Client built without NewClient.
+func (h *Handlers) isEmpty() bool {
+	return h.Sign.Len() == 0 && h.Send.Len() == 0 && h.Unmarshal.Len() == 0 &&
+		h.ErrorUnmarshal.Len() == 0 && h.Retry.Len() == 0 && h.AfterRetry.Len() == 0
+}
+
+// Clear removes callback functions for all handlers.
+func (h *Handlers) Clear() {
+	h.Sign.Clear()
+	h.Send.Clear()
+	h.Unmarshal.Clear()
+	h.ErrorUnmarshal.Clear()
+	h.Retry.Clear()
+	h.AfterRetry.Clear()
 }
 
-func (h *HandlerFunction) copy() HandlerFunction {
-	n := HandlerFunction{
-		Name: h.Name,
-		Fn:   h.Fn,
+// A HandlerListRunItem represents an entry in the HandlerList which
+// is being run.
+type HandlerListRunItem struct {
+	Index   int
+	Handler HandlerFunction
+	Request *Request
+}
+
+// A HandlerList manages zero or more handlers in a list.
+type HandlerList struct {
+	list []HandlerFunction
+
+	// Called after each request handler in the list is called. If set
+	// and the func returns true the HandlerList will continue to iterate
+	// over the request handlers. If false is returned the HandlerList
+	// will stop iterating.
+	//
+	// Should be used if extra logic to be performed between each handler
+	// in the list. This can be used to terminate a list's iteration
+	// based on a condition such as error like, HandlerListStopOnError.
+	AfterEachFn func(item HandlerListRunItem) bool
+}
+
+// copy creates a copy of the handler list.
+func (l *HandlerList) copy() HandlerList {
+	n := HandlerList{
+		AfterEachFn: l.AfterEachFn,
 	}
+	if len(l.list) == 0 {
+		return n
+	}
+
+	n.list = append(make([]HandlerFunction, 0, len(l.list)), l.list...)
 	return n
 }
 
+// Clear clears the handler list.
+func (l *HandlerList) Clear() {
+	l.list = l.list[0:0]
+}
+
+// Len returns the number of handlers in the list.
+func (l *HandlerList) Len() int {
+	return len(l.list)
+}
+
+// PushBack pushes handler f to the back of the handler list.
+func (l *HandlerList) PushBack(f func(*Request)) {
+	l.PushBackNamed(HandlerFunction{Name: "__anonymous", Fn: f})
+}
+
+// PushBackNamed pushes named handler f to the back of the handler list.
+func (l *HandlerList) PushBackNamed(n HandlerFunction) {
+	if cap(l.list) == 0 {
+		l.list = make([]HandlerFunction, 0, 5)
+	}
+	l.list = append(l.list, n)
+}
+
+// PushFront pushes handler f to the front of the handler list.
+func (l *HandlerList) PushFront(f func(*Request)) {
+	l.PushFrontNamed(HandlerFunction{Name: "__anonymous", Fn: f})
+}
+
+// PushFrontNamed pushes named handler f to the front of the handler list.
+func (l *HandlerList) PushFrontNamed(n HandlerFunction) {
+	if cap(l.list) == len(l.list) {
+		// Allocating new list required
+		l.list = append([]HandlerFunction{n}, l.list...)
+	} else {
+		// Enough room to prepend into list.
+		l.list = append(l.list, HandlerFunction{})
+		copy(l.list[1:], l.list)
+		l.list[0] = n
+	}
+}
+
+// Remove removes all handlers in the list with the given name.
+func (l *HandlerList) Remove(name string) {
+	for i := 0; i < len(l.list); i++ {
+		m := l.list[i]
+		if m.Name != name {
+			continue
+		}
+
+		// Shift array preventing creating new arrays
+		copy(l.list[i:], l.list[i+1:])
+		l.list[len(l.list)-1] = HandlerFunction{}
+		l.list = l.list[:len(l.list)-1]
+
+		// decrement list so next check to length is correct
+		i--
+	}
+}
+
+// Swap will swap out all handlers matching the name passed in. The matched
+// handlers will be swapped in. True is returned if the handlers were swapped.
+func (l *HandlerList) Swap(name string, replace HandlerFunction) bool {
+	var swapped bool
+
+	for i := 0; i < len(l.list); i++ {
+		if l.list[i].Name == name {
+			l.list[i] = replace
+			swapped = true
+		}
+	}
+
+	return swapped
+}
+
+// Run executes all handlers in the list with a given request object.
+func (l *HandlerList) Run(r *Request) {
+	for i, h := range l.list {
+		h.Run(r)
+		item := HandlerListRunItem{
+			Index: i, Handler: h, Request: r,
+		}
+		if l.AfterEachFn != nil && !l.AfterEachFn(item) {
+			return
+		}
+	}
+}
+
+// HandlerListStopOnError returns false to stop the HandlerList iterating
+// over request handlers if Request.Error is not nil. True otherwise
+// to continue iterating.
+func HandlerListStopOnError(item HandlerListRunItem) bool {
+	return item.Request.Error == nil
+}
+
+// A HandlerFunction is a struct that contains a name and function callback.
+type HandlerFunction struct {
+	Name string
+	Fn   func(*Request)
+}
+
 // Run executes callback function.
 func (h HandlerFunction) Run(r *Request) {
 	if h.Fn != nil {
@@ -91,39 +254,94 @@ var Signer = HandlerFunction{
 	Name: "Signer",
 	Fn: func(r *Request) {
 
-		token, err := r.Config.Credentials.Retrieve()
+		value, err := r.retrieveCredentials()
 		if err != nil {
 			r.Error = err
+			return
 		}
-		r.HTTPRequest.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.BearerToken))
+		r.Error = signHTTPRequest(r.HTTPRequest, r.EndPointInfo, value)
 	},
 }
 
 // ErrorUnmarshaler unmarshals the API request's errors and adds
-// an error to Request if errors are in HTTP Response body
+// an *APIError to Request if errors are in HTTP Response body
 var ErrorUnmarshaler = HandlerFunction{
 	Name: "ErrorUnmarshaler",
 	Fn: func(r *Request) {
 		if r.HTTPResponse.StatusCode >= 400 {
-			var diag Diagnostic
-			err := UnmarshalJSON(&diag, r.HTTPResponse.Body)
-			if err != nil {
-				r.Error = NewRequestFailure(
-					err,
-					r.HTTPResponse.StatusCode,
-					"Failed to decode JSON response to detect errors",
-				)
+			defer r.HTTPResponse.Body.Close()
+			apiErr := &APIError{
+				HTTPStatusCode: r.HTTPResponse.StatusCode,
+				EndPointName:   r.EndPointInfo.Name,
+				Header:         r.HTTPResponse.Header,
 			}
+			apiErr.Err = UnmarshalJSON(&apiErr.Diagnostic, r.HTTPResponse.Body)
+			r.Error = apiErr
+		}
+	},
+}
 
-			if diag.IsFiled() {
-				r.Error = NewRequestFailure(
-					nil,
-					r.HTTPResponse.StatusCode,
-					fmt.Sprintf("An error ocurred in sending a request to: %s with error details: %v", r.EndPointInfo.String(), diag.String()),
-				)
-			}
+// RefreshCredentialsHandler is a request handler which refreshes the
+// credentials once and retries the request when the Twitter API rejects them
+// as unauthorized, for example because a token was revoked early. Only
+// credentials retrieved from a provider or holding an OAuth 2.0 refresh token
+// can be refreshed.
+var RefreshCredentialsHandler = HandlerFunction{
+	Name: "RefreshCredentialsHandler",
+	Fn: func(r *Request) {
+		if r.refreshedCredentials || !IsUnauthorized(r.Error) || !r.Config.Credentials.canRefresh() {
+			return
+		}
+		r.refreshedCredentials = true
+		r.Config.Credentials.Expire()
+		r.Error = nil
+		r.Retryable = Bool(true)
+	},
+}
 
+// RetryHandler is a request handler to determine if a failed request
+// should be retried.
+var RetryHandler = HandlerFunction{
+	Name: "RetryHandler",
+	Fn: func(r *Request) {
+		// If one of the other handlers already set the retry state
+		// we don't want to override it based on the retryer's decision.
+		if r.Retryable == nil {
+			r.Retryable = Bool(r.ShouldRetry(r))
+		}
+	},
+}
+
+// AfterRetryHandler is a request handler that waits for the retry delay and
+// resets the request error once the request is going to be retried. When
+// Config.WaitOnRateLimit is set, rate limited requests wait until the rate
+// limit window reported in the response headers resets instead of the delay
+// of the retryer.
+var AfterRetryHandler = HandlerFunction{
+	Name: "AfterRetryHandler",
+	Fn: func(r *Request) {
+		if !r.WillRetry() {
+			return
+		}
+
+		if delay, ok := rateLimitDelay(r); ok && r.Config.WaitOnRateLimit && isErrorThrottle(r.Error) {
+			r.RetryDelay = delay
+		} else {
+			r.RetryDelay = r.RetryRules(r)
 		}
+		r.Config.Logger.Debug().
+			Str("endpoint", r.EndPointInfo.Name).
+			Int("retry_count", r.RetryCount).
+			Dur("retry_delay", r.RetryDelay).
+			Msg("Retrying request")
+		if err := sleepWithContext(r.Context(), r.RetryDelay); err != nil {
+			r.Error = NewRequestFailure(err, 0, "request context canceled")
+			r.Retryable = Bool(false)
+			return
+		}
+
+		r.RetryCount++
+		r.Error = nil
 	},
 }
 
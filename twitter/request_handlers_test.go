@@ -0,0 +1,70 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerList(t *testing.T) {
+	var calls []string
+	named := func(name string) HandlerFunction {
+		return HandlerFunction{Name: name, Fn: func(r *Request) { calls = append(calls, name) }}
+	}
+
+	var l HandlerList
+	l.PushBackNamed(named("b"))
+	l.PushBackNamed(named("c"))
+	l.PushFrontNamed(named("a"))
+	l.PushBackNamed(named("d"))
+	l.Remove("c")
+	assert.True(t, l.Swap("d", named("e")))
+	assert.False(t, l.Swap("missing", named("f")))
+
+	l.Run(&Request{})
+	assert.Equal(t, []string{"a", "b", "e"}, calls)
+	assert.Equal(t, 3, l.Len())
+
+	copied := l.copy()
+	copied.PushBackNamed(named("g"))
+	assert.Equal(t, 3, l.Len())
+	assert.Equal(t, 4, copied.Len())
+}
+
+func TestHandlerListStopOnError(t *testing.T) {
+	var calls int
+	l := HandlerList{AfterEachFn: HandlerListStopOnError}
+	l.PushBack(func(r *Request) { calls++ })
+	l.PushBack(func(r *Request) { calls++; r.Error = errors.New("failed") })
+	l.PushBack(func(r *Request) { calls++ })
+
+	r := &Request{}
+	l.Run(r)
+	assert.Equal(t, 2, calls)
+	assert.NotNil(t, r.Error)
+}
+
+func (suite *twitterClientSuite) Test_ClientHandlers() {
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().Equal("audit", r.Header.Get("X-Audit"))
+		fmt.Fprintf(w, `{"data": []}`)
+	})
+
+	suite.client.Handlers.Sign.PushBack(func(r *Request) {
+		r.HTTPRequest.Header.Set("X-Audit", "audit")
+	})
+	var validated bool
+	suite.client.Handlers.Unmarshal.PushBackNamed(HandlerFunction{
+		Name: "Validator",
+		Fn:   func(r *Request) { validated = true },
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Assert().True(validated)
+}
@@ -0,0 +1,62 @@
+package twitter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndPointInfo_buildURL(t *testing.T) {
+	apiInfo := newAPIInfo(NewConfig().WithEndpoint("http://localhost:8080/"))
+
+	cases := []struct {
+		endpoint *EndPointInfo
+		expected string
+	}{
+		{
+			&EndPointInfo{HTTPPath: "tweets/search/stream/rules", QueryParams: map[string]string{"dry_run": "true"}},
+			"http://localhost:8080/2/tweets/search/stream/rules?dry_run=true",
+		},
+		{
+			&EndPointInfo{HTTPPath: "statuses/update.json", APIVersion: "1.1"},
+			"http://localhost:8080/1.1/statuses/update.json",
+		},
+		{
+			&EndPointInfo{HTTPPath: "media/upload.json", APIVersion: "1.1", Endpoint: "https://upload.twitter.com"},
+			"https://upload.twitter.com/1.1/media/upload.json",
+		},
+	}
+
+	for _, c := range cases {
+		u, err := c.endpoint.buildURL(apiInfo)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, u.String())
+	}
+	assert.Equal(t, EndPoint, newAPIInfo(NewConfig()).Endpoint)
+}
+
+func TestNewAPIInfoEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"api.x.com":              "https://api.x.com",
+		"api.x.com/":             "https://api.x.com",
+		"https://api.x.com/":     "https://api.x.com",
+		"http://localhost:8080/": "http://localhost:8080",
+		"localhost:8080":         "https://localhost:8080",
+	}
+	for endpoint, expected := range cases {
+		apiInfo := newAPIInfo(NewConfig().WithEndpoint(endpoint))
+		assert.Nil(t, apiInfo.err, endpoint)
+		assert.Equal(t, expected, apiInfo.Endpoint, endpoint)
+
+		u, err := (&EndPointInfo{HTTPPath: "tweets/search/stream"}).buildURL(apiInfo)
+		assert.Nil(t, err, endpoint)
+		assert.Equal(t, expected+"/2/tweets/search/stream", u.String(), endpoint)
+	}
+
+	for _, endpoint := range []string{"http://", "https://api.x.com:port", "/2"} {
+		apiInfo := newAPIInfo(NewConfig().WithEndpoint(endpoint))
+		_, err := (&EndPointInfo{HTTPPath: "tweets/search/stream"}).buildURL(apiInfo)
+		assert.True(t, errors.Is(err, ErrInvalidEndpoint), endpoint)
+	}
+}
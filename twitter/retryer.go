@@ -1,11 +1,36 @@
 package twitter
 
 import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
 const maxDefaultRetries = 5
 
+const (
+	// DefaultRetryerMinRetryDelay is the minimum retry delay used by the
+	// DefaultRetryer for retryable server and network errors.
+	DefaultRetryerMinRetryDelay = 30 * time.Millisecond
+
+	// DefaultRetryerMinThrottleDelay is the minimum retry delay used by the
+	// DefaultRetryer when the request was rate limited.
+	DefaultRetryerMinThrottleDelay = 500 * time.Millisecond
+
+	// DefaultRetryerMaxRetryDelay is the maximum retry delay used by the
+	// DefaultRetryer for retryable server and network errors.
+	DefaultRetryerMaxRetryDelay = 300 * time.Second
+
+	// DefaultRetryerMaxThrottleDelay is the maximum retry delay used by the
+	// DefaultRetryer when the request was rate limited.
+	DefaultRetryerMaxThrottleDelay = 300 * time.Second
+)
+
 // Retryer provides the interface for the client request retry behavior. The
 // Retryer implementation is responsible for implementing exponential backoff,
 // and determine if a request API error should be retried.
@@ -26,6 +51,96 @@ type Retryer interface {
 	MaxRetries() int
 }
 
+// DefaultRetryer implements basic retry logic using exponential backoff with
+// jitter. Server errors, network errors and rate limited requests are
+// retried, client errors are not.
+//
+// Zero value delays are replaced with the DefaultRetryer* constants.
+type DefaultRetryer struct {
+	// NumMaxRetries is the number of times a request may be retried.
+	NumMaxRetries int
+
+	// MinRetryDelay is the minimum retry delay after which a retryable
+	// request will be retried.
+	MinRetryDelay time.Duration
+
+	// MinThrottleDelay is the minimum retry delay after which a rate
+	// limited request will be retried.
+	MinThrottleDelay time.Duration
+
+	// MaxRetryDelay is the maximum retry delay before which a retryable
+	// request will be retried.
+	MaxRetryDelay time.Duration
+
+	// MaxThrottleDelay is the maximum retry delay before which a rate
+	// limited request will be retried.
+	MaxThrottleDelay time.Duration
+}
+
+// MaxRetries returns the number of maximum retries the DefaultRetryer will
+// perform for a request.
+func (d DefaultRetryer) MaxRetries() int {
+	return d.NumMaxRetries
+}
+
+// RetryRules returns the delay duration before retrying this request again.
+// The delay grows exponentially with the retry count and is jittered over
+// the upper half of the backoff window so concurrent clients spread out.
+func (d DefaultRetryer) RetryRules(r *Request) time.Duration {
+	d.setRetryerDefaults()
+
+	minDelay, maxDelay := d.MinRetryDelay, d.MaxRetryDelay
+	if isErrorThrottle(r.Error) {
+		minDelay, maxDelay = d.MinThrottleDelay, d.MaxThrottleDelay
+	}
+
+	backoff := maxDelay
+	// Shifting past 30 would overflow time.Duration for the default delays,
+	// by then the backoff is capped at maxDelay anyway.
+	if r.RetryCount < 30 {
+		if delay := minDelay << uint(r.RetryCount); delay > 0 && delay < maxDelay {
+			backoff = delay
+		}
+	}
+
+	half := backoff / 2
+	return half + time.Duration(retryJitter.Int63n(int64(half)+1))
+}
+
+// ShouldRetry returns true if the request should be retried.
+func (d DefaultRetryer) ShouldRetry(r *Request) bool {
+	// If one of the other handlers already set the retry state
+	// we don't want to override it based on the error.
+	if r.Retryable != nil {
+		return *r.Retryable
+	}
+	// Requests whose context is done would fail again right away.
+	if r.Context().Err() != nil {
+		return false
+	}
+	// The Twitter API may have processed a request which is not idempotent
+	// before failing, only replay it if it was rejected before that.
+	if !r.EndPointInfo.IsIdempotent() {
+		return isErrorThrottle(r.Error) || isDialError(r.Error)
+	}
+	return isErrorRetryable(r.Error) || isErrorThrottle(r.Error)
+}
+
+func (d *DefaultRetryer) setRetryerDefaults() {
+	if d.MinRetryDelay == 0 {
+		d.MinRetryDelay = DefaultRetryerMinRetryDelay
+	}
+	if d.MaxRetryDelay == 0 {
+		d.MaxRetryDelay = DefaultRetryerMaxRetryDelay
+	}
+	if d.MinThrottleDelay == 0 {
+		d.MinThrottleDelay = DefaultRetryerMinThrottleDelay
+	}
+	if d.MaxThrottleDelay == 0 {
+		d.MaxThrottleDelay = DefaultRetryerMaxThrottleDelay
+	}
+}
+
 // noRetryer should be used when a request is created without a retryer.
 type noRetryer struct{}
 
@@ -40,3 +155,80 @@ func (d noRetryer) ShouldRetry(*Request) bool {
 func (d noRetryer) RetryRules(*Request) time.Duration {
 	return 0
 }
+
+// isErrorThrottle returns whether the error was caused by the Twitter API
+// rate limiting the request.
+func isErrorThrottle(err error) bool {
+	var rf RequestFailure
+	if !errors.As(err, &rf) {
+		return false
+	}
+	return rf.StatusCode() == http.StatusTooManyRequests
+}
+
+// isErrorRetryable returns whether the error was caused by a server side
+// failure or a network failure which is worth another attempt.
+func isErrorRetryable(err error) bool {
+	var rf RequestFailure
+	if !errors.As(err, &rf) {
+		return false
+	}
+
+	switch code := rf.StatusCode(); {
+	case code == 0:
+		origErr, ok := rf.(interface{ OrigErr() error })
+		return ok && isNetworkError(origErr.OrigErr())
+	case code == http.StatusNotImplemented:
+		return false
+	case code >= http.StatusInternalServerError:
+		return true
+	}
+	return false
+}
+
+// isNetworkError returns whether the error was returned by the transport
+// while dialing, writing or reading the connection.
+func isNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// url.Error implements net.Error itself, unwrap it so malformed requests
+	// are not mistaken for connection failures.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isDialError returns whether the error was returned while connecting to the
+// Twitter API, before anything was sent.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryJitter is the random source used to jitter retry delays.
+var retryJitter = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
+
+// lockedSource is a rand.Source which is safe for concurrent use.
+type lockedSource struct {
+	lk  sync.Mutex
+	src rand.Source
+}
+
+func (r *lockedSource) Int63() int64 {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	return r.src.Int63()
+}
+
+func (r *lockedSource) Seed(seed int64) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.src.Seed(seed)
+}
@@ -0,0 +1,174 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRetryer(maxRetries int) DefaultRetryer {
+	return DefaultRetryer{
+		NumMaxRetries:    maxRetries,
+		MinRetryDelay:    time.Millisecond,
+		MaxRetryDelay:    5 * time.Millisecond,
+		MinThrottleDelay: time.Millisecond,
+		MaxThrottleDelay: 5 * time.Millisecond,
+	}
+}
+
+func (suite *twitterClientSuite) Test_DefaultRetryerRetriesServerErrors() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"data": [{"id": "1165037377523306497", "value": "dog has:images"}]}`)
+	})
+
+	req, out := suite.client.GetRules(&GetRulesInput{})
+	req.Retryer = newTestRetryer(3)
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal(3, attempts)
+	suite.Assert().Equal(2, req.RetryCount)
+	suite.Assert().Equal(1, len(out.Data))
+}
+
+func (suite *twitterClientSuite) Test_DefaultRetryerStopsAtMaxRetries() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"title": "Too Many Requests", "detail": "Too Many Requests", "type": "about:blank"}`)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	req.Retryer = newTestRetryer(2)
+	err := req.Send()
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(3, attempts)
+	suite.Assert().Equal(2, req.RetryCount)
+}
+
+func (suite *twitterClientSuite) Test_DefaultRetryerSkipsClientErrors() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"title": "Invalid Request", "detail": "One or more parameters to your request was invalid.", "type": "https://api.twitter.com/2/problems/invalid-request"}`)
+	})
+
+	req, _ := suite.client.GetRules(&GetRulesInput{})
+	req.Retryer = newTestRetryer(3)
+	err := req.Send()
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(1, attempts)
+	suite.Assert().Equal(0, req.RetryCount)
+}
+
+func TestDefaultRetryer_RetryRules(t *testing.T) {
+	retryer := DefaultRetryer{NumMaxRetries: 10}
+	serverErr := NewRequestFailure(nil, http.StatusInternalServerError, "")
+	throttleErr := NewRequestFailure(nil, http.StatusTooManyRequests, "")
+
+	cases := []struct {
+		err        error
+		retryCount int
+		min, max   time.Duration
+	}{
+		{serverErr, 0, 15 * time.Millisecond, 30 * time.Millisecond},
+		{serverErr, 3, 120 * time.Millisecond, 240 * time.Millisecond},
+		{serverErr, 40, 150 * time.Second, 300 * time.Second},
+		{throttleErr, 0, 250 * time.Millisecond, 500 * time.Millisecond},
+		{throttleErr, 2, time.Second, 2 * time.Second},
+	}
+
+	for _, c := range cases {
+		r := &Request{Error: c.err, RetryCount: c.retryCount}
+		for i := 0; i < 50; i++ {
+			delay := retryer.RetryRules(r)
+			assert.True(t, delay >= c.min && delay <= c.max,
+				"retry %d: expected delay in [%s, %s], got %s", c.retryCount, c.min, c.max, delay)
+		}
+	}
+}
+
+func TestDefaultRetryer_ShouldRetry(t *testing.T) {
+	retryer := DefaultRetryer{}
+	get := &EndPointInfo{HTTPMethod: "GET"}
+	post := &EndPointInfo{HTTPMethod: "POST"}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	cases := []struct {
+		endpoint *EndPointInfo
+		err      error
+		expected bool
+	}{
+		{get, NewRequestFailure(nil, http.StatusServiceUnavailable, ""), true},
+		{get, NewRequestFailure(nil, http.StatusTooManyRequests, ""), true},
+		{get, NewRequestFailure(nil, http.StatusNotImplemented, ""), false},
+		{get, NewRequestFailure(nil, http.StatusUnauthorized, ""), false},
+		{get, NewRequestFailure(errors.New("unsupported protocol scheme"), 0, ""), false},
+		{get, NewRequestFailure(readErr, 0, ""), true},
+		{get, errors.New("not a request failure"), false},
+		{post, NewRequestFailure(nil, http.StatusServiceUnavailable, ""), false},
+		{post, NewRequestFailure(readErr, 0, ""), false},
+		{post, NewRequestFailure(dialErr, 0, ""), true},
+		{post, NewRequestFailure(nil, http.StatusTooManyRequests, ""), true},
+	}
+
+	for _, c := range cases {
+		r := &Request{EndPointInfo: c.endpoint, Error: c.err}
+		assert.Equal(t, c.expected, retryer.ShouldRetry(r), c.endpoint.HTTPMethod+" "+c.err.Error())
+	}
+}
+
+func (suite *twitterClientSuite) Test_RetryReplaysRequestBody() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		suite.Assert().JSONEq(`{"delete": {"ids": ["1166895166390583299"]}}`, string(body))
+		suite.Assert().Equal([]string{"Bearer TEST"}, r.Header.Values("Authorization"))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintf(w, `{"meta": {"sent": "2019-08-29T01:48:54.633Z", "summary": {"deleted": 1, "not_deleted": 0}}}`)
+	})
+
+	req, out := suite.client.DeleteRules(&DeleteRulesInput{RulesIDs{[]string{"1166895166390583299"}}})
+	req.Retryer = newTestRetryer(3)
+	err := req.Send()
+
+	suite.Assert().Nil(err)
+	suite.Assert().Equal(3, attempts)
+	suite.Assert().Equal(1, out.Meta.Summary.Deleted)
+}
+
+func (suite *twitterClientSuite) Test_RetrySkipsNonIdempotentRequests() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream/rules", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req, _ := suite.client.CreateRules(&CreateRulesInput{[]Rule{{Value: "cats has:media"}}})
+	req.Retryer = newTestRetryer(3)
+	err := req.Send()
+
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal(1, attempts)
+}
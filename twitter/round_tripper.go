@@ -0,0 +1,180 @@
+package twitter
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// A RoundTripper is an http.RoundTripper which signs outgoing requests with
+// the same credentials logic as the client's Signer, so endpoints this
+// package does not wrap yet can be called with any net/http client:
+//
+//	httpClient := &http.Client{
+//	    Transport: twitter.NewRoundTripper(nil, creds),
+//	}
+//	resp, err := httpClient.Get("https://api.twitter.com/2/users/me")
+//
+// Expiring OAuth 2.0 access tokens are refreshed before they are used, and a
+// request rejected with 401 Unauthorized is sent once more with refreshed
+// credentials when they can be refreshed and the body can be replayed.
+//
+// Requests are signed with the credentials set on their context with
+// ContextWithCredentials, or with those of the user set with ContextWithUserID,
+// looked up in the config's credential store. Other requests are signed with
+// the RoundTripper's Credentials, or the config's credentials when not set.
+//
+// The rate limit of every response is recorded in RateLimits, keyed by the
+// request's method and path, for example "GET /2/users/me".
+type RoundTripper struct {
+	// The transport requests are sent with. Defaults to
+	// `http.DefaultTransport`.
+	Base http.RoundTripper
+
+	// The credentials to sign requests with.
+	Credentials *Credentials
+
+	// The config the credentials and credential store are read from when
+	// each request is sent, if any.
+	Config *Config
+
+	// The tracker rate limits are recorded in. Rate limits are not recorded
+	// when nil.
+	RateLimits *RateLimitTracker
+}
+
+// NewRoundTripper returns a pointer to a new RoundTripper which signs requests
+// with the credentials and sends them with the base transport. If base is nil
+// `http.DefaultTransport` is used.
+func NewRoundTripper(base http.RoundTripper, creds *Credentials) *RoundTripper {
+	return &RoundTripper{
+		Base:        base,
+		Credentials: creds,
+		RateLimits:  NewRateLimitTracker(),
+	}
+}
+
+// RoundTripper returns a RoundTripper which signs requests with the
+// credentials of the client's config, as they are when each request is sent.
+// Rate limits are recorded in a tracker of the RoundTripper's own, as they are
+// keyed by method and path rather than by the endpoint names the client's
+// tracker uses.
+func (c *Client) RoundTripper(base http.RoundTripper) *RoundTripper {
+	return &RoundTripper{
+		Base:       base,
+		Config:     c.Config,
+		RateLimits: NewRateLimitTracker(),
+	}
+}
+
+type roundTripperContextKey int
+
+const (
+	userIDContextKey roundTripperContextKey = iota
+	credentialsContextKey
+)
+
+// ContextWithUserID returns a copy of the context which makes a RoundTripper
+// sign requests sent with it on behalf of the user, with the credentials
+// looked up in the config's credential store.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// ContextWithCredentials returns a copy of the context which makes a
+// RoundTripper sign requests sent with it with the credentials.
+func ContextWithCredentials(ctx context.Context, creds *Credentials) context.Context {
+	return context.WithValue(ctx, credentialsContextKey, creds)
+}
+
+// RoundTrip signs and sends the request. The request is not modified, a
+// signed copy of it is sent instead.
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, err := t.credentials(req.Context())
+	if err == nil && creds == nil {
+		err = ErrCredentialsEmpty
+	}
+	if err != nil {
+		// The transport must close the body, even when the request is not sent.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, value, err := t.roundTrip(req, creds, req.Body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !creds.canRefresh() {
+		return resp, err
+	}
+
+	body := req.Body
+	if body != nil && body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		if body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+
+	resp.Body.Close()
+	// Requests rejected concurrently refresh the credentials once: the
+	// credentials are only expired while they still hold the rejected token.
+	creds.expireValue(value)
+	resp, _, err = t.roundTrip(req, creds, body)
+	return resp, err
+}
+
+// credentials returns the credentials to sign requests sent with the context.
+func (t *RoundTripper) credentials(ctx context.Context) (*Credentials, error) {
+	if creds, ok := ctx.Value(credentialsContextKey).(*Credentials); ok && creds != nil {
+		return creds, nil
+	}
+
+	var cfg Config
+	if t.Config != nil {
+		cfg = *t.Config
+	}
+	if t.Credentials != nil {
+		cfg.Credentials = t.Credentials
+	}
+	userID, _ := ctx.Value(userIDContextKey).(string)
+	return resolveCredentials(ctx, cfg, userID)
+}
+
+// roundTrip sends a copy of the request with the body, signed with the
+// credentials, and returns the credentials value it was signed with.
+func (t *RoundTripper) roundTrip(req *http.Request, creds *Credentials, body io.ReadCloser) (*http.Response, Value, error) {
+	// The transport must close the body, even when the request is not sent.
+	closeBody := func() {
+		if body != nil {
+			body.Close()
+		}
+	}
+	value, err := creds.RetrieveWithContext(req.Context())
+	if err != nil {
+		closeBody()
+		return nil, Value{}, err
+	}
+
+	signed := req.Clone(req.Context())
+	signed.Body = body
+	if err := signHTTPRequest(signed, nil, value); err != nil {
+		closeBody()
+		return nil, Value{}, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(signed)
+	if err != nil {
+		return nil, Value{}, err
+	}
+
+	if limit := parseRateLimit(resp.Header); limit != nil {
+		t.RateLimits.Set(req.Method+" "+req.URL.Path, *limit)
+	}
+	return resp, value, nil
+}
@@ -0,0 +1,155 @@
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_RoundTripperSignsRequests() {
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().Equal("Bearer TEST", r.Header.Get("Authorization"))
+		w.Header().Set(rateLimitLimitHeader, "75")
+		w.Header().Set(rateLimitRemainingHeader, "74")
+		w.Header().Set(rateLimitResetHeader, "1640995200")
+		fmt.Fprintf(w, `{"data": {}}`)
+	})
+
+	transport := suite.client.RoundTripper(nil)
+	httpClient := &http.Client{Transport: transport}
+	req, _ := http.NewRequest("GET", suite.server.URL+"/2/users/me", nil)
+	resp, err := httpClient.Do(req)
+
+	suite.Require().Nil(err)
+	resp.Body.Close()
+	suite.Assert().Equal(http.StatusOK, resp.StatusCode)
+	suite.Assert().Equal("", req.Header.Get("Authorization"))
+
+	limit, ok := transport.RateLimits.Get("GET /2/users/me")
+	suite.Require().True(ok)
+	suite.Assert().Equal(74, limit.Remaining)
+	_, ok = suite.client.RateLimits.Get("GET /2/users/me")
+	suite.Assert().False(ok)
+}
+
+func (suite *twitterClientSuite) Test_RoundTripperRefreshesOnUnauthorized() {
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "refresh_token": "rotated"}`)
+	})
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		suite.Assert().JSONEq(`{"text": "Hello world!"}`, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"data": {"id": "1445880548472328192", "text": "Hello world!"}}`)
+	})
+
+	creds := NewOAuth2Credentials(suite.newOAuth2Config(), &OAuth2Token{
+		AccessToken:  "revoked",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	})
+	httpClient := &http.Client{Transport: NewRoundTripper(http.DefaultTransport, creds)}
+	resp, err := httpClient.Post(suite.server.URL+"/2/tweets", "application/json", strings.NewReader(`{"text": "Hello world!"}`))
+
+	suite.Require().Nil(err)
+	resp.Body.Close()
+	suite.Assert().Equal(http.StatusCreated, resp.StatusCode)
+	suite.Assert().Equal(2, attempts)
+}
+
+func (suite *twitterClientSuite) Test_RoundTripperRequiresCredentials() {
+	httpClient := &http.Client{Transport: NewRoundTripper(nil, NewCredentials(Value{}))}
+	_, err := httpClient.Get(suite.server.URL + "/2/users/me")
+
+	suite.Assert().ErrorIs(err, ErrCredentialsEmpty)
+}
+
+func (suite *twitterClientSuite) Test_RoundTripperResolvesCredentialsPerRequest() {
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"auth": %q}}`, r.Header.Get("Authorization"))
+	})
+	suite.client.Config.WithCredentialStore(CredentialStoreFunc(func(ctx context.Context, userID string) (*Credentials, error) {
+		return NewCredentials(Value{BearerToken: "user-" + userID}), nil
+	}))
+	httpClient := &http.Client{Transport: suite.client.RoundTripper(nil)}
+
+	send := func(ctx context.Context) string {
+		req, _ := http.NewRequestWithContext(ctx, "GET", suite.server.URL+"/2/users/me", nil)
+		resp, err := httpClient.Do(req)
+		suite.Require().Nil(err)
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	suite.Assert().JSONEq(`{"data": {"auth": "Bearer TEST"}}`, send(context.Background()))
+	suite.Assert().JSONEq(`{"data": {"auth": "Bearer user-2244994945"}}`, send(ContextWithUserID(context.Background(), "2244994945")))
+	suite.Assert().JSONEq(`{"data": {"auth": "Bearer TENANT"}}`,
+		send(ContextWithCredentials(context.Background(), NewCredentials(Value{BearerToken: "TENANT"}))))
+
+	// Credentials changed on the config after the RoundTripper was built are used.
+	suite.client.Config.WithCredentials(NewCredentials(Value{BearerToken: "ROTATED"}))
+	suite.Assert().JSONEq(`{"data": {"auth": "Bearer ROTATED"}}`, send(context.Background()))
+}
+
+// countingProvider returns a new bearer token every time it is retrieved.
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Retrieve() (Value, error) {
+	p.calls++
+	return Value{BearerToken: fmt.Sprintf("token-%d", p.calls)}, nil
+}
+
+func (p *countingProvider) IsExpired() bool {
+	return false
+}
+
+func (suite *twitterClientSuite) Test_RoundTripperRefreshesOnceOnConcurrentUnauthorized() {
+	const concurrency = 8
+	var rejected sync.WaitGroup
+	rejected.Add(concurrency)
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			// Hold the responses until every request was sent with the token.
+			rejected.Done()
+			rejected.Wait()
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {}}`)
+	})
+
+	provider := &countingProvider{}
+	creds := NewCredentialsFromProvider(provider)
+	_, err := creds.Retrieve()
+	suite.Require().Nil(err)
+	httpClient := &http.Client{Transport: NewRoundTripper(nil, creds)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := httpClient.Get(suite.server.URL + "/2/users/me")
+			if suite.Assert().Nil(err) {
+				resp.Body.Close()
+				suite.Assert().Equal(http.StatusOK, resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Assert().Equal(2, provider.calls)
+}
@@ -23,28 +23,18 @@ func Test_twitterClientSuite(t *testing.T) {
 func (suite *twitterClientSuite) SetupTest() {
 	mux := http.NewServeMux()
 	server := httptest.NewServer(mux)
-	transport := &RewriteTransport{&http.Transport{
-		Proxy: func(req *http.Request) (*url.URL, error) {
-			return url.Parse(server.URL)
-		},
-	}}
-	httpClient := &http.Client{Transport: transport}
 
 	config := NewConfig().
 		WithCredentials(NewCredentials(Value{
 			BearerToken: "TEST",
 		})).
-		WithHTTPClient(httpClient).
+		WithEndpoint(server.URL).
 		WithLogLevel(int8(2)).
 		WithLogger(newDefaultLogger()).
-		WithRetryer(noRetryer{})
+		WithRetryer(noRetryer{}).
+		WithStreamRetryer(noStreamRetryer{})
 
-	client := &Client{
-		Config:  config,
-		APIInfo: newAPIInfo(),
-	}
-
-	suite.client = client
+	suite.client = NewClient(config)
 	suite.server = server
 	suite.mux = mux
 
@@ -55,16 +45,20 @@ func (suite *twitterClientSuite) Test_Close() {
 
 }
 
-type RewriteTransport struct {
-	Transport http.RoundTripper
-}
-
-func (t *RewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.URL.Scheme = "http"
-	if t.Transport == nil {
-		return http.DefaultTransport.RoundTrip(req)
+// newUserContextRequest returns a request to the authenticated user lookup
+// endpoint, which supports user context auth only.
+func (suite *twitterClientSuite) newUserContextRequest() *Request {
+	endpoint := &EndPointInfo{
+		Name:       "FindMyUser",
+		HTTPMethod: "GET",
+		HTTPPath:   "users/me",
+		AuthTypes:  []AuthType{AuthOAuth2User, AuthOAuth1User},
+		Scopes:     []string{ScopeTweetRead, ScopeUsersRead},
 	}
-	return t.Transport.RoundTrip(req)
+	output := &struct {
+		Data interface{} `json:"data"`
+	}{}
+	return suite.client.NewRequest(endpoint, nil, output)
 }
 
 // assertQuery tests that the Request has the expected url query key/val pairs
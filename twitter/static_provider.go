@@ -0,0 +1,43 @@
+package twitter
+
+import "errors"
+
+// StaticProviderName provides a name of the static provider
+const StaticProviderName = "StaticProvider"
+
+var (
+	// ErrStaticCredentialsEmpty is emitted when static credentials are empty.
+	ErrStaticCredentialsEmpty = errors.New("EmptyStaticCreds: static credentials are empty")
+)
+
+// A StaticProvider is a set of credentials which are set programmatically,
+// and will never expire.
+type StaticProvider struct {
+	Value
+}
+
+// NewStaticCredentials returns a pointer to a new Credentials object
+// wrapping a static credentials value provider.
+func NewStaticCredentials(value Value) *Credentials {
+	return NewCredentialsFromProvider(&StaticProvider{Value: value})
+}
+
+// Retrieve returns the credentials or error if the credentials are invalid.
+func (s *StaticProvider) Retrieve() (Value, error) {
+	if s.Value.isEmpty() {
+		return Value{ProviderName: StaticProviderName}, ErrStaticCredentialsEmpty
+	}
+
+	v := s.Value
+	if v.ProviderName == "" {
+		v.ProviderName = StaticProviderName
+	}
+	return v, nil
+}
+
+// IsExpired returns if the credentials are expired.
+//
+// For StaticProvider, the credentials never expired.
+func (s *StaticProvider) IsExpired() bool {
+	return false
+}
@@ -2,19 +2,21 @@ package twitter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
+	"reflect"
 	"sync"
 	"time"
 )
 
 // Stream connects to a streaming endpoint on the Twitter API.
 // It receives messages from the streaming endpoint and sends them on the
-// Queue channel from a goroutine.
+// MessageQueue channel from a goroutine, or on the Messages channel along
+// with the details of their delivery.
 type Stream struct {
 	Config       Config
 	APIInfo      APIInfo
@@ -24,70 +26,169 @@ type Stream struct {
 	HTTPRequest  *http.Request
 	HTTPResponse *http.Response
 	Retryable    *bool
+	RetryCount   int
+	RetryDelay   time.Duration
 	PayLoad      interface{}
 	Error        error
-	Data         interface{}
 	Handlers     StreamHandlers
-	Retryer
+	StreamRetryer
+
+	// Data is a pointer value of the type messages are deserialized to. Every
+	// message is deserialized to a new value of the type.
+	Data interface{}
+
+	// ConnectionID identifies the connection of the stream. It starts at 1
+	// and increases every time the stream reconnects.
+	ConnectionID int
+
+	// StallTimeout is the time the stream waits for data or a keep-alive
+	// before it reconnects. Defaults to DefaultStreamStallTimeout, and a
+	// negative timeout disables stall detection.
+	StallTimeout time.Duration
+
+	// UserID is the ID of the user the stream connects on behalf of. The
+	// stream request is signed with the user's credentials from the config's
+	// credential store.
+	UserID string
+
+	// MessageQueue receives the deserialized Data of every message, which is
+	// a pointer to a new value of the stream's Data type. Messages are sent
+	// either on the MessageQueue or on the Messages channel, so a stream
+	// should be consumed through one of them.
 	MessageQueue chan interface{}
-	rawData      chan []byte
-	done         chan struct{}
-	errorChan    chan error
-	waitGroup    *sync.WaitGroup
-	body         io.ReadCloser
+
+	messages      chan *StreamMessage
+	rawData       chan *StreamMessage
+	done          chan struct{}
+	errorChan     chan error
+	waitGroup     *sync.WaitGroup
+	body          io.ReadCloser
+	bodyLock      sync.Mutex
+	heartbeatLock sync.Mutex
+	lastHeartbeat time.Time
+	context       context.Context
+	consumed      chan struct{}
+	errLock       sync.Mutex
+	err           error
+	tweets        chan *StreamTweetsMessage
+	tweetsOnce    sync.Once
+	startOnce     sync.Once
+	rateLimiter   *RateLimiter
+	manualStart   bool
+}
+
+// A StreamMessage is a message received on a stream, along with the details
+// of its delivery.
+type StreamMessage struct {
+	// The time the message was received.
+	ReceivedAt time.Time
+
+	// The ID of the stream connection the message was received on.
+	ConnectionID int
+
+	// The raw bytes of the message.
+	Raw []byte
+
+	// Data is a pointer to a new value of the stream's Data type the message
+	// was deserialized to, such as *StreamTweetsOutput.
+	Data interface{}
+}
+
+// A StreamOption is a functional option that can augment or modify a stream
+// before it connects, when using a streaming operation method such as
+// StreamTweets.
+type StreamOption func(*Stream)
+
+// WithStreamCredentials is a stream option which signs the stream request
+// with the credentials instead of the config's credentials.
+func WithStreamCredentials(creds *Credentials) StreamOption {
+	return func(s *Stream) {
+		s.Config.Credentials = creds
+	}
 }
 
-// NewStream returns a new stream object that is connect to a streaming endpoint.
-// If there is no error in the stream connection you can start reading messages
-// from the Queue channel
-func (c *Client) NewStream(endpoint *EndPointInfo, input, output interface{}) *Stream {
-	return createStream(*c.Config, c.APIInfo, c.Retryer, endpoint, input, output)
+// WithManualStart is a stream option which keeps the stream from connecting
+// in the background once it is created. The stream is connected with Connect,
+// which returns the error the stream could not be established with, or with
+// Start.
+func WithManualStart() StreamOption {
+	return func(s *Stream) {
+		s.manualStart = true
+	}
+}
+
+// WithStreamUserID is a stream option which signs the stream request on
+// behalf of the user, with the credentials looked up in the config's
+// credential store.
+func WithStreamUserID(userID string) StreamOption {
+	return func(s *Stream) {
+		s.UserID = userID
+	}
 }
 
-func createStream(cfg Config, apiInfo APIInfo,
-	retryer Retryer, endpointInfo *EndPointInfo, payLoad interface{}, data interface{}) *Stream {
+// NewStream returns a new stream object for a streaming endpoint. The stream
+// connects in the background as soon as it is created, and messages can be
+// read from the MessageQueue channel.
+//
+// With the WithManualStart option the stream is not connected until Connect
+// or Start is called. A stream which is never started should still be
+// stopped with Stop, which closes its channels.
+func (c *Client) NewStream(endpoint *EndPointInfo, input, output interface{}, opts ...StreamOption) *Stream {
+	return c.NewStreamWithContext(backgroundCtx, endpoint, input, output, opts...)
+}
+
+// NewStreamWithContext is the same as NewStream with the addition of a context.
+// The stream's HTTP request is bound to the context, and the stream stops
+// and closes its Queue channel once the context is done. The options are
+// applied before the stream connects.
+//
+// Panics if a nil context is passed in.
+func (c *Client) NewStreamWithContext(ctx context.Context, endpoint *EndPointInfo, input, output interface{}, opts ...StreamOption) *Stream {
+	if ctx == nil {
+		panic("context cannot be nil")
+	}
+	s := createStream(ctx, *c.Config, c.APIInfo, c.StreamHandlers, c.StreamRetryer, endpoint, input, output, opts...)
+	s.rateLimiter = c.RateLimiter
+	if !s.manualStart {
+		s.Start()
+	}
+	return s
+}
+
+func createStream(ctx context.Context, cfg Config, apiInfo APIInfo, handlers StreamHandlers,
+	retryer StreamRetryer, endpointInfo *EndPointInfo, payLoad interface{}, data interface{}, opts ...StreamOption) *Stream {
 	var err error
 
 	if retryer == nil {
-		retryer = noRetryer{}
+		retryer = noStreamRetryer{}
+	}
+	if handlers.isEmpty() {
+		handlers = DefaultStreamHandlers()
 	}
 
 	if err = endpointInfo.Validate(); err != nil {
 		return &Stream{Error: err}
 	}
 
-	httpReq, err := http.NewRequest(endpointInfo.HTTPMethod, "", nil)
+	httpReq, err := http.NewRequestWithContext(ctx, endpointInfo.HTTPMethod, "", nil)
 	if err != nil {
 		return &Stream{Error: err}
 	}
 
 	httpReq.Header.Add("Content-type", "application/json")
 
-	httpReq.URL, err = url.Parse(apiInfo.Endpoint + "/" + apiInfo.APIVersion + "/" + endpointInfo.HTTPPath)
+	httpReq.URL, err = endpointInfo.buildURL(apiInfo)
 	if err != nil {
 		httpReq.URL = &url.URL{}
 		return &Stream{Error: err}
 	}
 
-	if endpointInfo.QueryParams != nil {
-		q := httpReq.URL.Query()
-		for k, v := range endpointInfo.QueryParams {
-			q.Add(k, v)
-		}
-		httpReq.URL.RawQuery = q.Encode()
-	}
-
 	if payLoad != nil {
 		b, err := json.Marshal(payLoad)
 		if err != nil {
 			return &Stream{Error: err}
 		}
-		httpReq.Body = ioutil.NopCloser(strings.NewReader(string(b)))
-	}
-
-	handlers := StreamHandlers{
-		Send: StreamSendHandler,
-		Sign: StreamSigner,
+		setRequestBody(httpReq, b)
 	}
 
 	s := &Stream{
@@ -96,55 +197,156 @@ func createStream(cfg Config, apiInfo APIInfo,
 		EndPointInfo: endpointInfo,
 		Handlers:     handlers.Copy(),
 
-		Retryer:      retryer,
-		Time:         time.Now(),
-		HTTPRequest:  httpReq,
-		waitGroup:    &sync.WaitGroup{},
-		PayLoad:      payLoad,
-		Error:        err,
-		Data:         data,
-		MessageQueue: make(chan interface{}),
-		rawData:      make(chan []byte),
-		done:         make(chan struct{}),
+		StreamRetryer: retryer,
+		StallTimeout:  cfg.StreamStallTimeout,
+		Time:          time.Now(),
+		HTTPRequest:   httpReq,
+		waitGroup:     &sync.WaitGroup{},
+		PayLoad:       payLoad,
+		Error:         err,
+		Data:          data,
+		MessageQueue:  make(chan interface{}),
+		messages:      make(chan *StreamMessage),
+		rawData:       make(chan *StreamMessage),
+		done:          make(chan struct{}),
+		errorChan:     make(chan error, streamErrorBufferSize),
+		consumed:      make(chan struct{}),
+		context:       ctx,
+	}
+	if s.StallTimeout == 0 {
+		s.StallTimeout = DefaultStreamStallTimeout
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
-	s.waitGroup.Add(2)
-	go s.consume()
-	go s.processMessage()
 
 	return s
 
 }
 
-func (s *Stream) consume() {
+// Connect connects the stream and blocks until the Twitter API responded. If
+// the stream could not be established, such as when the credentials are
+// rejected or there are too many connections, the stream ends and the error is
+// returned, which is an *APIError for error responses. Otherwise the stream
+// starts receiving messages in the background, and reconnects as guided by its
+// StreamRetryer once it is disconnected.
+//
+// Returns ErrStreamStarted if the stream was started already, which streams
+// created without the WithManualStart option are.
+func (s *Stream) Connect() error {
+	if s.done == nil {
+		return s.Error
+	}
 
-	defer close(s.rawData)
+	err := ErrStreamStarted
+	s.startOnce.Do(func() {
+		err = s.connect()
+		if err != nil {
+			s.Config.Logger.Error().Err(err).Msg("Failed to connect stream")
+			connectErr := err
+			s.start(func() error { return connectErr })
+			return
+		}
+		s.start(func() error { return s.run(true) })
+	})
+	return err
+}
+
+// Start connects the stream in the background, and returns immediately. The
+// stream reconnects as guided by its StreamRetryer, and Err returns the error
+// it ended with once its MessageQueue is closed. Starting a stream which was
+// started already, such as one created without the WithManualStart option,
+// has no effect.
+func (s *Stream) Start() {
+	if s.done == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.start(func() error { return s.run(false) })
+	})
+}
 
+// start starts the goroutines which run the stream and process its messages.
+func (s *Stream) start(run func() error) {
+	s.waitGroup.Add(2)
+	go s.consume(run)
+	go s.processMessage()
+}
+
+func (s *Stream) consume(run func() error) {
+	defer close(s.consumed)
+	defer close(s.rawData)
 	defer s.waitGroup.Done()
-	for !s.stopped() {
-		s.Error = nil
-		s.AttemptTime = time.Now()
 
-		if err := s.sign(); err != nil {
-			s.Config.Logger.Error().Err(err).Msg("Failed to sign stream request")
-			return
+	s.setErr(run())
+}
+
+// connect signs and sends the stream request, and returns the error the
+// stream could not be established with.
+func (s *Stream) connect() error {
+	s.Error = nil
+	s.AttemptTime = time.Now()
+
+	if err := s.sign(); err != nil {
+		return err
+	}
+	return s.sendRequest()
+}
+
+// run receives the stream's messages and reconnects it until it is stopped,
+// and returns the error it ended with otherwise. The stream is connected
+// first, unless it is connected already.
+func (s *Stream) run(connected bool) error {
+	for !s.stopped() {
+		if !connected {
+			s.Error = nil
+			s.AttemptTime = time.Now()
+
+			if err := s.sign(); err != nil {
+				s.Config.Logger.Error().Err(err).Msg("Failed to sign stream request")
+				return err
+			}
+			connected = s.sendRequest() == nil
 		}
 
-		if err := s.sendRequest(); err == nil {
-			s.receive(s.body)
+		if connected {
+			s.Error = s.receive(s.body)
+			s.closeBody()
+			connected = false
+		}
+		if s.stopped() {
+			break
 		}
 
 		s.Handlers.Retry.Run(s)
+		s.Handlers.AfterRetry.Run(s)
+		if s.stopped() {
+			break
+		}
 
 		if s.Error != nil || !BoolValue(s.Retryable) {
 			s.Config.Logger.Error().Err(s.Error).Msg("stream request can not be retried")
-			return
+			return s.Error
 		}
-
 	}
+	return s.Context().Err()
+}
 
+// retrieveCredentials retrieves the credentials to sign the stream request
+// with. The credentials of the user the stream connects on behalf of are
+// looked up again for every connection attempt.
+func (s *Stream) retrieveCredentials() (Value, error) {
+	creds, err := resolveCredentials(s.Context(), s.Config, s.UserID)
+	if err != nil {
+		return Value{}, err
+	}
+	if creds == nil {
+		return Value{}, ErrCredentialsEmpty
+	}
+	s.Config.Credentials = creds
+	return creds.RetrieveWithContext(s.Context())
 }
 
-// Sign will sign the request, returning error if errors are encountered.
 func (s *Stream) sign() error {
 	s.Handlers.Sign.Run(s)
 	return s.Error
@@ -156,96 +358,195 @@ func (s *Stream) sendRequest() (sendErr error) {
 	if s.Error != nil {
 		return s.Error
 	}
+
+	s.Handlers.ErrorUnmarshal.Run(s)
+	if s.Error != nil {
+		return s.Error
+	}
+	s.bodyLock.Lock()
 	s.body = s.HTTPResponse.Body
+	s.bodyLock.Unlock()
+	s.ConnectionID++
+	s.heartbeat()
 	return nil
 
 }
 
+// closeBody closes the response body of the current connection, if any.
+func (s *Stream) closeBody() {
+	s.bodyLock.Lock()
+	defer s.bodyLock.Unlock()
+	if s.body != nil {
+		s.body.Close()
+	}
+}
+
+// sleep waits for the duration to pass, or returns false if the stream was
+// stopped first.
+func (s *Stream) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-s.done:
+		return false
+	case <-s.Context().Done():
+		return false
+	}
+}
+
+// Context returns the context the stream is bound to, or a background context
+// if none was set.
+func (s *Stream) Context() context.Context {
+	if s.context != nil {
+		return s.context
+	}
+	return backgroundCtx
+}
+
 func (s *Stream) stopped() bool {
 	select {
 	case <-s.done:
 		return true
+	case <-s.Context().Done():
+		return true
 	default:
 		return false
 	}
 }
 
-// Stop signals retry and receiver to stop, closes the Messages channel, and
-// blocks until done.
+// Stop signals retry and receiver to stop, closes the MessageQueue and Errors
+// channels, and blocks until done. The channels of a stream which was never
+// started are closed as well, and the stream can not be started afterwards.
 func (s *Stream) Stop() {
+	if s.done == nil {
+		return
+	}
 	close(s.done)
+	// Run the stream's goroutines, which end right away, to close its
+	// channels if it was never started.
+	s.startOnce.Do(func() {
+		s.start(func() error { return nil })
+	})
 	// Scanner does not have a Stop() or take a done channel, so for low volume
 	// streams Scan() blocks until the next keep-alive. Close the resp.Body to
 	// escape and stop the stream in a timely fashion.
-	if s.body != nil {
-		s.body.Close()
-	}
+	s.closeBody()
 	// block until the retry goroutine stops
 	s.waitGroup.Wait()
 }
 
-func (s *Stream) receive(body io.Reader) {
-	reader := newStreamResponseBodyReader(body)
+// receive reads messages off the response body until the stream is stopped,
+// and returns the error the stream was disconnected with otherwise. Keep-alives
+// count as heartbeats, and the connection is closed once it stalls.
+func (s *Stream) receive(body io.Reader) error {
+	watchdog := s.watchStall()
+	defer watchdog.stop()
+
+	reader := newStreamResponseBodyReader(&heartbeatReader{reader: body, stream: s})
 	for !s.stopped() {
 		data, err := reader.readNext()
 		if err != nil {
+			if watchdog.fired() {
+				err = ErrStreamStalled
+			}
 			message := "failed to read next tweet from streaming response body"
 			s.Config.Logger.Error().Err(err).Msg(message)
-			return
+			return NewRequestFailure(err, 0, "stream disconnected")
 		}
+		// The stream is established once data arrives, the backoff starts over.
+		s.RetryCount = 0
 		if len(data) == 0 {
 			// empty keep-alive
 			continue
 		}
 
+		// The reader reuses its buffer for the next message.
+		message := &StreamMessage{
+			ReceivedAt:   time.Now(),
+			ConnectionID: s.ConnectionID,
+			Raw:          append([]byte(nil), data...),
+		}
+
+		// Waiting for the consumer to take the message does not count as a
+		// stall of the connection.
+		watchdog.pause()
 		select {
 		// send messages, data, or errors
-		case s.rawData <- data:
+		case s.rawData <- message:
+			watchdog.resume()
 			continue
 
 		// allow client to Stop(), even if not receiving
 		case <-s.done:
-			return
+			return nil
+
+		// allow the context to end the stream, even if not receiving
+		case <-s.Context().Done():
+			return nil
 		}
 	}
+	return nil
+}
+
+// Messages returns a channel of the messages of the stream along with the
+// details of their delivery, which is closed along with the MessageQueue.
+func (s *Stream) Messages() <-chan *StreamMessage {
+	return s.messages
 }
 
 func (s *Stream) processMessage() {
 	defer close(s.MessageQueue)
+	defer close(s.messages)
+	defer close(s.errorChan)
 	defer s.waitGroup.Done()
+	// The stream ends once the consumer has settled its error.
+	defer func() { <-s.consumed }()
 	for !s.stopped() {
-		messageBytes, ok := <-s.rawData
+		message, ok := <-s.rawData
 		if !ok {
 			return
 		}
-		message, err := s.getMessage(messageBytes)
-		if err != nil {
-			message := "Failed to get message from raw data"
-			s.Config.Logger.Error().Err(err).Msg(message)
-			s.Retryable = Bool(false)
-			return
+		if err := s.getMessage(message); err != nil {
+			s.reportError(&MalformedMessageError{ConnectionID: message.ConnectionID, Raw: message.Raw, Err: err})
+			continue
 		}
 
 		select {
 		// send messages, data, or errors
-		case s.MessageQueue <- message:
+		case s.messages <- message:
+			continue
+		case s.MessageQueue <- message.Data:
 			continue
 
 		// allow client to Stop(), even if not receiving
 		case <-s.done:
 			return
+
+		// allow the context to end the stream, even if not receiving
+		case <-s.Context().Done():
+			return
 		}
 	}
 
 }
 
-func (s *Stream) getMessage(messageBytes []byte) (interface{}, error) {
-	reader := bytes.NewReader(messageBytes)
-	err := UnmarshalJSON(s.Data, reader)
+// getMessage deserializes the message's raw bytes to a new value of the
+// stream's Data type.
+func (s *Stream) getMessage(message *StreamMessage) error {
+	t := reflect.TypeOf(s.Data)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T", ErrInvalidStreamData, s.Data)
+	}
+	data := reflect.New(t.Elem()).Interface()
+	err := UnmarshalJSON(data, bytes.NewReader(message.Raw))
 	if err != nil {
 		message := "Failed to unmarshal bytes in json"
 		s.Config.Logger.Error().Err(err).Msg(message)
-		return nil, err
+		return err
 	}
-	return s.Data, nil
+	message.Data = data
+	return nil
 }
@@ -0,0 +1,111 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrStreamStarted is returned when connecting a stream which was started
+	// already.
+	ErrStreamStarted = errors.New("StreamStarted: stream was started already")
+
+	// ErrInvalidStreamData is reported for every message of a stream whose
+	// Data is not a pointer the messages can be deserialized to.
+	ErrInvalidStreamData = errors.New("InvalidStreamData: stream Data must be a non-nil pointer")
+)
+
+// streamErrorBufferSize is the number of non-fatal errors a stream buffers
+// for the Errors channel. Errors are dropped while the buffer is full.
+const streamErrorBufferSize = 16
+
+// A MalformedMessageError is reported on a stream's Errors channel when a
+// message could not be deserialized. The message is skipped, and the stream
+// continues with the next message.
+type MalformedMessageError struct {
+	// The ID of the stream connection the message was received on.
+	ConnectionID int
+
+	// The raw bytes of the message.
+	Raw []byte
+
+	// The error encountered while deserializing the message.
+	Err error
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *MalformedMessageError) Error() string {
+	return SprintError("failed to deserialize stream message", fmt.Sprintf("connection: %d", e.ConnectionID), e.Err)
+}
+
+// Unwrap returns the error encountered while deserializing the message.
+func (e *MalformedMessageError) Unwrap() error {
+	return e.Err
+}
+
+// A StreamReconnectError is reported on a stream's Errors channel when the
+// stream was disconnected and is about to reconnect.
+type StreamReconnectError struct {
+	// The ID of the stream connection which was disconnected.
+	ConnectionID int
+
+	// The number of times in a row the stream reconnected before.
+	RetryCount int
+
+	// The delay before the stream reconnects.
+	Delay time.Duration
+
+	// The error the stream was disconnected with.
+	Err error
+}
+
+// Error returns the string representation of the error.
+// Satisfies the error interface.
+func (e *StreamReconnectError) Error() string {
+	extra := fmt.Sprintf("connection: %d, retry: %d, delay: %s", e.ConnectionID, e.RetryCount, e.Delay)
+	return SprintError("stream disconnected, reconnecting", extra, e.Err)
+}
+
+// Unwrap returns the error the stream was disconnected with.
+func (e *StreamReconnectError) Unwrap() error {
+	return e.Err
+}
+
+// Errors returns a channel of the non-fatal problems the stream runs into,
+// such as malformed messages and reconnects. The channel is closed along with
+// the MessageQueue. Reading it is optional; errors are dropped while its
+// buffer is full.
+func (s *Stream) Errors() <-chan error {
+	return s.errorChan
+}
+
+// Err returns the error the stream ended with once its MessageQueue is
+// closed, such as an error it could not reconnect after, or the error of its
+// context. It returns nil while the stream runs, and after it was stopped
+// with Stop.
+func (s *Stream) Err() error {
+	if s.consumed == nil {
+		return s.Error
+	}
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+	return s.err
+}
+
+func (s *Stream) setErr(err error) {
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+	s.err = err
+}
+
+// reportError sends the non-fatal error on the Errors channel, unless its
+// buffer is full.
+func (s *Stream) reportError(err error) {
+	select {
+	case s.errorChan <- err:
+	default:
+		s.Config.Logger.Debug().Err(err).Msg("dropped stream error, the errors channel is full")
+	}
+}
@@ -0,0 +1,126 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_StreamReportsMalformedMessages() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "this is not JSON\r\n")
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	defer stream.Stop()
+
+	select {
+	case message := <-stream.MessageQueue:
+		suite.Assert().Equal("1067094924124872705", message.(*StreamTweetsOutput).Data.ID)
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not skip the malformed message")
+	}
+
+	select {
+	case err := <-stream.Errors():
+		var malformed *MalformedMessageError
+		suite.Require().True(errors.As(err, &malformed))
+		suite.Assert().Equal("this is not JSON", string(malformed.Raw))
+		suite.Assert().Equal(1, malformed.ConnectionID)
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not report the malformed message")
+	}
+}
+
+func (suite *twitterClientSuite) Test_StreamReportsInvalidData() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	endpoint := &EndPointInfo{Name: streamTweets, HTTPMethod: "GET", HTTPPath: "tweets/search/stream", AuthTypes: []AuthType{AuthAppOnly}}
+	stream := suite.client.NewStream(endpoint, nil, nil, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	defer stream.Stop()
+
+	select {
+	case err := <-stream.Errors():
+		suite.Assert().True(errors.Is(err, ErrInvalidStreamData))
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not report the invalid data")
+	}
+}
+
+func (suite *twitterClientSuite) Test_StreamReportsReconnects() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{})
+
+	select {
+	case <-stream.MessageQueue:
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not reconnect")
+	}
+	stream.Stop()
+
+	var errs []error
+	for err := range stream.Errors() {
+		errs = append(errs, err)
+	}
+	suite.Require().Equal(1, len(errs))
+	var reconnect *StreamReconnectError
+	suite.Require().True(errors.As(errs[0], &reconnect))
+	suite.Assert().Equal(0, reconnect.RetryCount)
+	suite.Assert().Equal(http.StatusServiceUnavailable, streamErrorStatusCode(reconnect))
+	suite.Assert().Nil(stream.Err())
+}
+
+func (suite *twitterClientSuite) Test_StreamErrAfterTerminalError() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{})
+
+	for range stream.MessageQueue {
+	}
+	suite.Require().NotNil(stream.Err())
+	suite.Assert().True(IsUnauthorized(stream.Err()))
+}
+
+func (suite *twitterClientSuite) Test_StreamErrAfterContextCanceled() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := suite.client.StreamTweetsWithContext(ctx, StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	suite.Assert().Nil(stream.Err())
+	cancel()
+
+	for range stream.MessageQueue {
+	}
+	suite.Assert().Equal(context.Canceled, stream.Err())
+}
@@ -2,18 +2,38 @@ package twitter
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 )
 
-// A StreamHandlers provides a collection of stream request handlers for various
-// stages of handling requests.
+// A StreamHandlers provides a collection of stream request handler lists for
+// various stages of handling requests.
 type StreamHandlers struct {
-	Sign           StreamHandlerFunction
-	Send           StreamHandlerFunction
-	Retry          StreamHandlerFunction
-	AfterRetry     StreamHandlerFunction
+	Sign           StreamHandlerList
+	Send           StreamHandlerList
+	ErrorUnmarshal StreamHandlerList
+	Retry          StreamHandlerList
+	AfterRetry     StreamHandlerList
+}
+
+// DefaultStreamHandlers returns the handler lists streams are created with.
+// The Sign, Send and ErrorUnmarshal lists stop at the first handler which
+// sets the stream's error.
+func DefaultStreamHandlers() StreamHandlers {
+	var handlers StreamHandlers
+
+	handlers.Sign.PushBackNamed(StreamClientRateLimitHandler)
+	handlers.Sign.PushBackNamed(StreamSigner)
+	handlers.Send.PushBackNamed(StreamSendHandler)
+	handlers.ErrorUnmarshal.PushBackNamed(StreamErrorUnmarshaler)
+	handlers.Retry.PushBackNamed(StreamRetryHandler)
+	handlers.AfterRetry.PushBackNamed(StreamAfterRetryHandler)
+
+	handlers.Sign.AfterEachFn = StreamHandlerListStopOnError
+	handlers.Send.AfterEachFn = StreamHandlerListStopOnError
+	handlers.ErrorUnmarshal.AfterEachFn = StreamHandlerListStopOnError
+
+	return handlers
 }
 
 // Copy returns a copy of this handler's lists.
@@ -21,25 +41,159 @@ func (h *StreamHandlers) Copy() StreamHandlers {
 	return StreamHandlers{
 		Sign:           h.Sign.copy(),
 		Send:           h.Send.copy(),
+		ErrorUnmarshal: h.ErrorUnmarshal.copy(),
 		Retry:          h.Retry.copy(),
 		AfterRetry:     h.AfterRetry.copy(),
 	}
 }
 
-// A StreamHandlerFunction is a struct that contains a name and function callback.
-type StreamHandlerFunction struct {
-	Name string
-	Fn   func(*Stream)
+// isEmpty returns true if none of the handler lists has a handler, as for
+// the zero value of a Client built without NewClient.
+func (h *StreamHandlers) isEmpty() bool {
+	return h.Sign.Len() == 0 && h.Send.Len() == 0 && h.ErrorUnmarshal.Len() == 0 &&
+		h.Retry.Len() == 0 && h.AfterRetry.Len() == 0
 }
 
-func (h *StreamHandlerFunction) copy() StreamHandlerFunction {
-	n := StreamHandlerFunction{
-		Name: h.Name,
-		Fn:   h.Fn,
+// Clear removes callback functions for all handlers.
+func (h *StreamHandlers) Clear() {
+	h.Sign.Clear()
+	h.Send.Clear()
+	h.ErrorUnmarshal.Clear()
+	h.Retry.Clear()
+	h.AfterRetry.Clear()
+}
+
+// A StreamHandlerListRunItem represents an entry in the StreamHandlerList
+// which is being run.
+type StreamHandlerListRunItem struct {
+	Index   int
+	Handler StreamHandlerFunction
+	Stream  *Stream
+}
+
+// A StreamHandlerList manages zero or more stream handlers in a list.
+type StreamHandlerList struct {
+	list []StreamHandlerFunction
+
+	// Called after each stream handler in the list is called. If set
+	// and the func returns true the StreamHandlerList will continue to
+	// iterate over the stream handlers. If false is returned the
+	// StreamHandlerList will stop iterating.
+	AfterEachFn func(item StreamHandlerListRunItem) bool
+}
+
+// copy creates a copy of the handler list.
+func (l *StreamHandlerList) copy() StreamHandlerList {
+	n := StreamHandlerList{
+		AfterEachFn: l.AfterEachFn,
+	}
+	if len(l.list) == 0 {
+		return n
 	}
+
+	n.list = append(make([]StreamHandlerFunction, 0, len(l.list)), l.list...)
 	return n
 }
 
+// Clear clears the handler list.
+func (l *StreamHandlerList) Clear() {
+	l.list = l.list[0:0]
+}
+
+// Len returns the number of handlers in the list.
+func (l *StreamHandlerList) Len() int {
+	return len(l.list)
+}
+
+// PushBack pushes handler f to the back of the handler list.
+func (l *StreamHandlerList) PushBack(f func(*Stream)) {
+	l.PushBackNamed(StreamHandlerFunction{Name: "__anonymous", Fn: f})
+}
+
+// PushBackNamed pushes named handler f to the back of the handler list.
+func (l *StreamHandlerList) PushBackNamed(n StreamHandlerFunction) {
+	if cap(l.list) == 0 {
+		l.list = make([]StreamHandlerFunction, 0, 5)
+	}
+	l.list = append(l.list, n)
+}
+
+// PushFront pushes handler f to the front of the handler list.
+func (l *StreamHandlerList) PushFront(f func(*Stream)) {
+	l.PushFrontNamed(StreamHandlerFunction{Name: "__anonymous", Fn: f})
+}
+
+// PushFrontNamed pushes named handler f to the front of the handler list.
+func (l *StreamHandlerList) PushFrontNamed(n StreamHandlerFunction) {
+	if cap(l.list) == len(l.list) {
+		// Allocating new list required
+		l.list = append([]StreamHandlerFunction{n}, l.list...)
+	} else {
+		// Enough room to prepend into list.
+		l.list = append(l.list, StreamHandlerFunction{})
+		copy(l.list[1:], l.list)
+		l.list[0] = n
+	}
+}
+
+// Remove removes all handlers in the list with the given name.
+func (l *StreamHandlerList) Remove(name string) {
+	for i := 0; i < len(l.list); i++ {
+		m := l.list[i]
+		if m.Name != name {
+			continue
+		}
+
+		// Shift array preventing creating new arrays
+		copy(l.list[i:], l.list[i+1:])
+		l.list[len(l.list)-1] = StreamHandlerFunction{}
+		l.list = l.list[:len(l.list)-1]
+
+		// decrement list so next check to length is correct
+		i--
+	}
+}
+
+// Swap will swap out all handlers matching the name passed in. The matched
+// handlers will be swapped in. True is returned if the handlers were swapped.
+func (l *StreamHandlerList) Swap(name string, replace StreamHandlerFunction) bool {
+	var swapped bool
+
+	for i := 0; i < len(l.list); i++ {
+		if l.list[i].Name == name {
+			l.list[i] = replace
+			swapped = true
+		}
+	}
+
+	return swapped
+}
+
+// Run executes all handlers in the list with a given stream object.
+func (l *StreamHandlerList) Run(s *Stream) {
+	for i, h := range l.list {
+		h.Run(s)
+		item := StreamHandlerListRunItem{
+			Index: i, Handler: h, Stream: s,
+		}
+		if l.AfterEachFn != nil && !l.AfterEachFn(item) {
+			return
+		}
+	}
+}
+
+// StreamHandlerListStopOnError returns false to stop the StreamHandlerList
+// iterating over stream handlers if Stream.Error is not nil. True otherwise
+// to continue iterating.
+func StreamHandlerListStopOnError(item StreamHandlerListRunItem) bool {
+	return item.Stream.Error == nil
+}
+
+// A StreamHandlerFunction is a struct that contains a name and function callback.
+type StreamHandlerFunction struct {
+	Name string
+	Fn   func(*Stream)
+}
 
 // Run executes callback function.
 func (h StreamHandlerFunction) Run(s *Stream) {
@@ -63,18 +217,76 @@ var StreamSendHandler = StreamHandlerFunction{
 	},
 }
 
+// StreamErrorUnmarshaler unmarshals the error response the stream could not be
+// established with into an *APIError, so the stream reconnects or ends
+// instead of reading the response body as messages.
+var StreamErrorUnmarshaler = StreamHandlerFunction{
+	Name: "ErrorUnmarshaler",
+	Fn: func(s *Stream) {
+		if s.HTTPResponse.StatusCode != http.StatusOK {
+			defer s.HTTPResponse.Body.Close()
+			apiErr := &APIError{
+				HTTPStatusCode: s.HTTPResponse.StatusCode,
+				EndPointName:   s.EndPointInfo.Name,
+				Header:         s.HTTPResponse.Header,
+			}
+			apiErr.Err = UnmarshalJSON(&apiErr.Diagnostic, s.HTTPResponse.Body)
+			s.Error = apiErr
+		}
+	},
+}
+
+// StreamRetryHandler is a stream handler which asks the stream's retryer
+// whether the disconnected stream should reconnect, unless a handler has
+// already decided.
+var StreamRetryHandler = StreamHandlerFunction{
+	Name: "RetryHandler",
+	Fn: func(s *Stream) {
+		if s.Retryable == nil {
+			s.Retryable = Bool(s.ShouldRetry(s))
+		}
+	},
+}
 
+// StreamAfterRetryHandler is a stream handler which waits out the retryer's
+// backoff before the stream reconnects. The wait is aborted when the stream is
+// stopped.
+var StreamAfterRetryHandler = StreamHandlerFunction{
+	Name: "AfterRetryHandler",
+	Fn: func(s *Stream) {
+		if !BoolValue(s.Retryable) {
+			return
+		}
+
+		s.RetryDelay = s.RetryRules(s)
+		s.Config.Logger.Debug().Err(s.Error).Dur("delay", s.RetryDelay).Msg("reconnecting stream")
+		s.reportError(&StreamReconnectError{
+			ConnectionID: s.ConnectionID,
+			RetryCount:   s.RetryCount,
+			Delay:        s.RetryDelay,
+			Err:          s.Error,
+		})
+		if !s.sleep(s.RetryDelay) {
+			s.Retryable = Bool(false)
+			return
+		}
+
+		s.RetryCount++
+		s.Error = nil
+	},
+}
 
 // StreamSigner is a stream handler to add the credentials to the stream request header.
 var StreamSigner = StreamHandlerFunction{
 	Name: "Signer",
 	Fn: func(s *Stream) {
 
-		token, err := s.Config.Credentials.Retrieve()
+		value, err := s.retrieveCredentials()
 		if err != nil {
 			s.Error = err
+			return
 		}
-		s.HTTPRequest.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.BearerToken))
+		s.Error = signHTTPRequest(s.HTTPRequest, s.EndPointInfo, value)
 	},
 }
 
@@ -102,5 +314,3 @@ func handleStreamSendError(s *Stream, err error) {
 	s.Error = NewRequestFailure(err, s.HTTPResponse.StatusCode, "send request failed")
 
 }
-
-
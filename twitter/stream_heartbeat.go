@@ -0,0 +1,131 @@
+package twitter
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStreamStallTimeout is the time a stream waits for data or a
+// keep-alive before it considers the connection stalled. Twitter sends a
+// keep-alive every 20 seconds on idle streams.
+const DefaultStreamStallTimeout = 30 * time.Second
+
+var (
+	// ErrStreamStalled is emitted when a stream is disconnected because it did
+	// not receive any data or keep-alive within its stall timeout.
+	ErrStreamStalled = errors.New("StreamStalled: no data received within the stall timeout")
+)
+
+// LastHeartbeat returns the time the stream last received data from the
+// Twitter API, including keep-alives, or the zero time if it never connected.
+func (s *Stream) LastHeartbeat() time.Time {
+	s.heartbeatLock.Lock()
+	defer s.heartbeatLock.Unlock()
+	return s.lastHeartbeat
+}
+
+func (s *Stream) heartbeat() {
+	s.heartbeatLock.Lock()
+	defer s.heartbeatLock.Unlock()
+	s.lastHeartbeat = time.Now()
+}
+
+// heartbeatReader records a heartbeat on the stream for every read which
+// returns data.
+type heartbeatReader struct {
+	reader io.Reader
+	stream *Stream
+}
+
+func (r *heartbeatReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.stream.heartbeat()
+	}
+	return n, err
+}
+
+// A stallWatchdog closes the body of a stream which did not receive a
+// heartbeat within its stall timeout, which unblocks the pending read so the
+// stream can reconnect. The watchdog is paused while the stream hands a
+// message off to a slow consumer, so only time spent waiting on the
+// connection counts towards the stall timeout.
+type stallWatchdog struct {
+	done    chan struct{}
+	exited  chan struct{}
+	stalled int32
+
+	// Set while the watchdog is paused.
+	paused int32
+
+	// The time the watchdog was last resumed, in Unix nanoseconds.
+	resumed int64
+}
+
+// watchStall starts a watchdog for the current connection of the stream. A
+// zero or negative stall timeout disables the watchdog.
+func (s *Stream) watchStall() *stallWatchdog {
+	w := &stallWatchdog{
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	if s.StallTimeout <= 0 {
+		close(w.exited)
+		return w
+	}
+
+	go func() {
+		defer close(w.exited)
+
+		t := time.NewTimer(s.StallTimeout)
+		defer t.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-t.C:
+				if atomic.LoadInt32(&w.paused) == 1 {
+					t.Reset(s.StallTimeout)
+					continue
+				}
+				last := s.LastHeartbeat()
+				if resumed := time.Unix(0, atomic.LoadInt64(&w.resumed)); resumed.After(last) {
+					last = resumed
+				}
+				idle := time.Since(last)
+				if idle < s.StallTimeout {
+					t.Reset(s.StallTimeout - idle)
+					continue
+				}
+				atomic.StoreInt32(&w.stalled, 1)
+				s.closeBody()
+				return
+			}
+		}
+	}()
+	return w
+}
+
+// pause pauses the watchdog until resume is called.
+func (w *stallWatchdog) pause() {
+	atomic.StoreInt32(&w.paused, 1)
+}
+
+// resume resumes the watchdog, which counts the stall timeout from now on.
+func (w *stallWatchdog) resume() {
+	atomic.StoreInt64(&w.resumed, time.Now().UnixNano())
+	atomic.StoreInt32(&w.paused, 0)
+}
+
+// stop stops the watchdog and waits for it to exit.
+func (w *stallWatchdog) stop() {
+	close(w.done)
+	<-w.exited
+}
+
+// fired returns true if the watchdog closed the stream's body.
+func (w *stallWatchdog) fired() bool {
+	return atomic.LoadInt32(&w.stalled) == 1
+}
@@ -0,0 +1,113 @@
+package twitter
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_StreamReconnectsWhenStalled() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fmt.Fprintf(w, `{"data": {"id": "%d", "text": "Twitter API is awesome!"}}`+"\r\n", attempts)
+		w.(http.Flusher).Flush()
+		// Go silent without closing the connection.
+		<-r.Context().Done()
+	})
+
+	suite.client.Config.StreamStallTimeout = 50 * time.Millisecond
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-stream.MessageQueue:
+			suite.Assert().NotNil(message)
+		case <-time.After(time.Second):
+			suite.FailNow("stalled stream did not reconnect")
+		}
+	}
+	stream.Stop()
+
+	suite.Assert().Equal(2, attempts)
+	suite.Assert().WithinDuration(time.Now(), stream.LastHeartbeat(), time.Second)
+}
+
+func (suite *twitterClientSuite) Test_StreamKeepAlivesPreventStall() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(w, "\r\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	suite.client.Config.StreamStallTimeout = 100 * time.Millisecond
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	select {
+	case message := <-stream.MessageQueue:
+		suite.Assert().NotNil(message)
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not receive the message")
+	}
+	stream.Stop()
+
+	suite.Assert().Equal(1, attempts)
+}
+
+func (suite *twitterClientSuite) Test_StreamSlowConsumerDoesNotStall() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		for i := 1; i <= 4; i++ {
+			fmt.Fprintf(w, `{"data": {"id": "%d", "text": "Twitter API is awesome!"}}`+"\r\n", i)
+		}
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+				fmt.Fprintf(w, "\r\n")
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+
+	suite.client.Config.StreamStallTimeout = 100 * time.Millisecond
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+
+	// The consumer takes longer than the stall timeout for every message.
+	for i := 0; i < 4; i++ {
+		time.Sleep(150 * time.Millisecond)
+		select {
+		case message := <-stream.Messages():
+			suite.Assert().Equal(1, message.ConnectionID)
+		case <-time.After(time.Second):
+			suite.FailNow("stream did not receive the message")
+		}
+	}
+	stream.Stop()
+
+	suite.Assert().Equal(1, attempts)
+	for err := range stream.Errors() {
+		suite.Fail("unexpected stream error", "%v", err)
+	}
+}
+
+func (suite *twitterClientSuite) Test_StreamLastHeartbeatBeforeConnecting() {
+	stream := &Stream{}
+	suite.Assert().True(stream.LastHeartbeat().IsZero())
+}
@@ -0,0 +1,167 @@
+package twitter
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultStreamNetworkMinDelay is the first reconnect delay, and the step
+	// by which it grows linearly, after a network error.
+	DefaultStreamNetworkMinDelay = 250 * time.Millisecond
+
+	// DefaultStreamNetworkMaxDelay is the maximum reconnect delay after a
+	// network error.
+	DefaultStreamNetworkMaxDelay = 16 * time.Second
+
+	// DefaultStreamHTTPMinDelay is the first reconnect delay after an HTTP
+	// error, which doubles with every reconnect.
+	DefaultStreamHTTPMinDelay = 5 * time.Second
+
+	// DefaultStreamHTTPMaxDelay is the maximum reconnect delay after an HTTP
+	// error.
+	DefaultStreamHTTPMaxDelay = 320 * time.Second
+
+	// DefaultStreamRateLimitMinDelay is the first reconnect delay after the
+	// stream was rate limited, which doubles with every reconnect.
+	DefaultStreamRateLimitMinDelay = time.Minute
+
+	// DefaultStreamRateLimitMaxDelay is the maximum reconnect delay after the
+	// stream was rate limited.
+	DefaultStreamRateLimitMaxDelay = 16 * time.Minute
+)
+
+// StreamRetryer provides the interface for the stream reconnect behavior. The
+// StreamRetryer implementation is responsible for the backoff between
+// reconnects, and determine if a disconnected stream should reconnect.
+type StreamRetryer interface {
+	// RetryRules return the delay that should be waited before reconnecting
+	// the disconnected stream.
+	RetryRules(*Stream) time.Duration
+
+	// ShouldRetry returns if the disconnected stream should reconnect.
+	ShouldRetry(*Stream) bool
+
+	// MaxRetries is the number of times in a row a stream may reconnect
+	// without receiving data before it ends.
+	MaxRetries() int
+}
+
+// DefaultStreamRetryer implements the reconnect policies documented for the
+// Twitter streaming endpoints:
+//
+// * Network errors, including disconnects of an established stream, back
+// off linearly, starting at 250ms up to 16 seconds.
+//
+// * HTTP errors back off exponentially, starting at 5 seconds up to 320
+// seconds.
+//
+// * Rate limited connections back off exponentially, starting at 1 minute.
+//
+// Client errors other than rate limiting, such as 401 Unauthorized, end the
+// stream. The backoff starts over once a reconnected stream receives data.
+//
+// Zero value delays are replaced with the DefaultStream* constants.
+type DefaultStreamRetryer struct {
+	// NumMaxRetries is the number of times in a row a stream may reconnect.
+	// The stream reconnects until it is stopped when zero.
+	NumMaxRetries int
+
+	// The first delay and the linear step, and the maximum delay, after
+	// network errors.
+	NetworkMinDelay time.Duration
+	NetworkMaxDelay time.Duration
+
+	// The first and maximum delay after HTTP errors.
+	HTTPMinDelay time.Duration
+	HTTPMaxDelay time.Duration
+
+	// The first and maximum delay after the stream was rate limited.
+	RateLimitMinDelay time.Duration
+	RateLimitMaxDelay time.Duration
+}
+
+// MaxRetries returns the number of times in a row a stream may reconnect.
+func (d DefaultStreamRetryer) MaxRetries() int {
+	return d.NumMaxRetries
+}
+
+// RetryRules returns the delay before reconnecting the stream, following the
+// policy of the error the stream was disconnected with.
+func (d DefaultStreamRetryer) RetryRules(s *Stream) time.Duration {
+	switch statusCode := streamErrorStatusCode(s.Error); {
+	case statusCode == http.StatusTooManyRequests:
+		return exponentialDelay(s.RetryCount,
+			durationOrDefault(d.RateLimitMinDelay, DefaultStreamRateLimitMinDelay),
+			durationOrDefault(d.RateLimitMaxDelay, DefaultStreamRateLimitMaxDelay))
+	case statusCode >= 400:
+		return exponentialDelay(s.RetryCount,
+			durationOrDefault(d.HTTPMinDelay, DefaultStreamHTTPMinDelay),
+			durationOrDefault(d.HTTPMaxDelay, DefaultStreamHTTPMaxDelay))
+	default:
+		step := durationOrDefault(d.NetworkMinDelay, DefaultStreamNetworkMinDelay)
+		max := durationOrDefault(d.NetworkMaxDelay, DefaultStreamNetworkMaxDelay)
+		if delay := step * time.Duration(s.RetryCount+1); delay < max && delay > 0 {
+			return delay
+		}
+		return max
+	}
+}
+
+// ShouldRetry returns true if the stream was disconnected by a network error,
+// a server error or rate limiting, and it did not reconnect more than
+// NumMaxRetries times in a row.
+func (d DefaultStreamRetryer) ShouldRetry(s *Stream) bool {
+	if s.stopped() {
+		return false
+	}
+	if d.NumMaxRetries > 0 && s.RetryCount >= d.NumMaxRetries {
+		return false
+	}
+	statusCode := streamErrorStatusCode(s.Error)
+	return statusCode < 400 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// streamErrorStatusCode returns the status code of the error the stream was
+// disconnected with, which is zero for network errors.
+func streamErrorStatusCode(err error) int {
+	var reqErr RequestFailure
+	if errors.As(err, &reqErr) {
+		return reqErr.StatusCode()
+	}
+	return 0
+}
+
+// noStreamRetryer should be used when a stream is created without a retryer.
+type noStreamRetryer struct{}
+
+func (d noStreamRetryer) MaxRetries() int {
+	return 0
+}
+
+func (d noStreamRetryer) ShouldRetry(*Stream) bool {
+	return false
+}
+
+func (d noStreamRetryer) RetryRules(*Stream) time.Duration {
+	return 0
+}
+
+// exponentialDelay returns min doubled retryCount times, capped at max.
+func exponentialDelay(retryCount int, min, max time.Duration) time.Duration {
+	if retryCount > 62 {
+		return max
+	}
+	if delay := min << uint(retryCount); delay < max && delay > 0 {
+		return delay
+	}
+	return max
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,129 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStreamRetryer(maxRetries int) DefaultStreamRetryer {
+	return DefaultStreamRetryer{
+		NumMaxRetries:     maxRetries,
+		NetworkMinDelay:   time.Millisecond,
+		NetworkMaxDelay:   5 * time.Millisecond,
+		HTTPMinDelay:      time.Millisecond,
+		HTTPMaxDelay:      5 * time.Millisecond,
+		RateLimitMinDelay: time.Millisecond,
+		RateLimitMaxDelay: 5 * time.Millisecond,
+	}
+}
+
+func TestDefaultStreamRetryerRetryRules(t *testing.T) {
+	networkErr := NewRequestFailure(errors.New("connection reset"), 0, "stream disconnected")
+	httpErr := NewRequestFailure(nil, http.StatusServiceUnavailable, "stream connection failed")
+	throttleErr := NewRequestFailure(nil, http.StatusTooManyRequests, "stream connection failed")
+
+	cases := []struct {
+		err        error
+		retryCount int
+		expected   time.Duration
+	}{
+		{networkErr, 0, 250 * time.Millisecond},
+		{networkErr, 3, time.Second},
+		{networkErr, 100, 16 * time.Second},
+		{httpErr, 0, 5 * time.Second},
+		{httpErr, 2, 20 * time.Second},
+		{httpErr, 10, 320 * time.Second},
+		{throttleErr, 0, time.Minute},
+		{throttleErr, 3, 8 * time.Minute},
+		{throttleErr, 100, 16 * time.Minute},
+	}
+
+	retryer := DefaultStreamRetryer{}
+	for _, c := range cases {
+		s := &Stream{Error: c.err, RetryCount: c.retryCount}
+		assert.Equal(t, c.expected, retryer.RetryRules(s), "%v, retry %d", c.err, c.retryCount)
+	}
+}
+
+func TestDefaultStreamRetryerShouldRetry(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		expected   bool
+	}{
+		{0, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusForbidden, false},
+	}
+
+	retryer := DefaultStreamRetryer{}
+	for _, c := range cases {
+		s := &Stream{Error: NewRequestFailure(nil, c.statusCode, "stream disconnected"), done: make(chan struct{})}
+		assert.Equal(t, c.expected, retryer.ShouldRetry(s), "status %d", c.statusCode)
+	}
+
+	s := &Stream{Error: NewRequestFailure(nil, 0, "stream disconnected"), RetryCount: 2, done: make(chan struct{})}
+	assert.False(t, DefaultStreamRetryer{NumMaxRetries: 2}.ShouldRetry(s))
+}
+
+func (suite *twitterClientSuite) Test_StreamReconnects() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			// Drop the connection after the first message.
+			fmt.Fprintf(w, `{"data": {"id": "1", "text": "first"}}`+"\r\n")
+		default:
+			fmt.Fprintf(w, `{"data": {"id": "2", "text": "second"}}`+"\r\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-stream.MessageQueue:
+			suite.Assert().NotNil(message)
+		case <-time.After(time.Second):
+			suite.FailNow("stream did not reconnect")
+		}
+	}
+	stream.Stop()
+
+	suite.Assert().Equal(3, attempts)
+	suite.Assert().Equal(0, stream.RetryCount)
+}
+
+func (suite *twitterClientSuite) Test_StreamEndsOnClientError() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{})
+
+	select {
+	case _, ok := <-stream.MessageQueue:
+		suite.Assert().False(ok)
+	case <-time.After(time.Second):
+		suite.FailNow("stream did not end")
+	}
+	stream.Stop()
+
+	suite.Assert().Equal(1, attempts)
+	suite.Assert().Equal(http.StatusUnauthorized, streamErrorStatusCode(stream.Error))
+}
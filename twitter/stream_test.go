@@ -0,0 +1,138 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (suite *twitterClientSuite) Test_StreamMessagesAreNotReused() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": {"id": "1", "text": "first", "lang": "en"}}`+"\r\n")
+		fmt.Fprintf(w, `{"data": {"id": "2", "text": "second"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	start := time.Now()
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	defer stream.Stop()
+
+	var messages []*StreamMessage
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-stream.Messages():
+			messages = append(messages, message)
+		case <-time.After(time.Second):
+			suite.FailNow("stream did not receive the messages")
+		}
+	}
+
+	first, second := messages[0].Data.(*StreamTweetsOutput), messages[1].Data.(*StreamTweetsOutput)
+	suite.Assert().Equal("1", first.Data.ID)
+	suite.Assert().Equal("first", first.Data.Text)
+	suite.Assert().Equal("2", second.Data.ID)
+	suite.Assert().Equal("", second.Data.Lang)
+
+	suite.Assert().Equal(`{"data": {"id": "1", "text": "first", "lang": "en"}}`, string(messages[0].Raw))
+	suite.Assert().Equal(`{"data": {"id": "2", "text": "second"}}`, string(messages[1].Raw))
+	for _, message := range messages {
+		suite.Assert().Equal(1, message.ConnectionID)
+		suite.Assert().WithinDuration(start, message.ReceivedAt, time.Second)
+	}
+}
+
+func (suite *twitterClientSuite) Test_StreamMessagesCarryConnectionID() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fmt.Fprintf(w, `{"data": {"id": "%d", "text": "Twitter API is awesome!"}}`+"\r\n", attempts)
+		if attempts > 1 {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	suite.Require().Nil(stream.Connect())
+	defer stream.Stop()
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case message := <-stream.Messages():
+			suite.Assert().Equal(i, message.ConnectionID)
+		case <-time.After(time.Second):
+			suite.FailNow("stream did not reconnect")
+		}
+	}
+}
+
+func (suite *twitterClientSuite) Test_StreamConnectReturnsAPIError() {
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"title": "ConnectionException", "detail": "This stream is currently at the maximum allowed connection limit.", "connection_issue": "TooManyConnections", "type": "https://api.twitter.com/2/problems/streaming-connection"}`)
+	})
+
+	suite.client.StreamRetryer = newTestStreamRetryer(3)
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	err := stream.Connect()
+
+	var apiErr *APIError
+	suite.Require().True(errors.As(err, &apiErr))
+	suite.Assert().Equal(http.StatusTooManyRequests, apiErr.StatusCode())
+	suite.Assert().Equal("ConnectionException", apiErr.Diagnostic.Title)
+	suite.Assert().Equal(streamTweets, apiErr.EndPointName)
+	suite.Assert().Equal("TooManyConnections", apiErr.Diagnostic.ConnectionIssue)
+	suite.Assert().True(IsTooManyConnections(err))
+	suite.Assert().False(IsTooManyConnections(&APIError{HTTPStatusCode: http.StatusTooManyRequests}))
+
+	// The stream ends without reconnecting or decoding the error as a message.
+	_, ok := <-stream.MessageQueue
+	suite.Assert().False(ok)
+	suite.Assert().Equal(err, stream.Err())
+	suite.Assert().Equal(ErrStreamStarted, stream.Connect())
+}
+
+func (suite *twitterClientSuite) Test_StreamManualStart() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fmt.Fprintf(w, `{"data": {"id": "1067094924124872705", "text": "Twitter API is awesome!"}}`+"\r\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	time.Sleep(20 * time.Millisecond)
+	suite.Assert().Equal(0, attempts)
+
+	suite.Require().Nil(stream.Connect())
+	stream.Start()
+	<-stream.MessageQueue
+	stream.Stop()
+	suite.Assert().Equal(1, attempts)
+}
+
+func (suite *twitterClientSuite) Test_StreamStopWithoutStart() {
+	attempts := 0
+	suite.mux.HandleFunc("/2/tweets/search/stream", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	})
+
+	stream := suite.client.StreamTweets(StreamTweetsInput{}, WithManualStart())
+	stream.Stop()
+
+	_, ok := <-stream.MessageQueue
+	suite.Assert().False(ok)
+	_, ok = <-stream.Errors()
+	suite.Assert().False(ok)
+	suite.Assert().Nil(stream.Err())
+
+	// The stopped stream can not be started anymore.
+	stream.Start()
+	suite.Assert().Equal(ErrStreamStarted, stream.Connect())
+	suite.Assert().Equal(0, attempts)
+}
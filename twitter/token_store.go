@@ -0,0 +1,160 @@
+package twitter
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultMaxCachedUsers is the number of users an OAuth2CredentialStore
+// caches the credentials of by default.
+const DefaultMaxCachedUsers = 1000
+
+var (
+	// ErrTokenNotFound is returned by token stores when there is no token
+	// stored for the account key.
+	ErrTokenNotFound = errors.New("TokenNotFound: no token stored for the account key")
+)
+
+// A TokenStore persists OAuth 2.0 user context tokens by account key, for
+// example the ID of the user who authorized the app, so they survive
+// restarts. Credentials created with NewStoredOAuth2Credentials load their
+// token from the store and write it back after every refresh.
+//
+// A TokenStore must be safe to use concurrently.
+type TokenStore interface {
+	// Get returns the token stored for the account key, or ErrTokenNotFound
+	// if there is none.
+	Get(ctx context.Context, key string) (*OAuth2Token, error)
+
+	// Put stores the token for the account key, replacing any stored token.
+	Put(ctx context.Context, key string, token *OAuth2Token) error
+
+	// Delete removes the token stored for the account key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// A MemoryTokenStore is a TokenStore which keeps tokens in memory. Tokens are
+// lost when the process exits.
+type MemoryTokenStore struct {
+	m      sync.RWMutex
+	tokens map[string]OAuth2Token
+}
+
+// NewMemoryTokenStore returns a pointer to a new empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]OAuth2Token)}
+}
+
+// Get returns a copy of the token stored for the account key.
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (*OAuth2Token, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+// Put stores a copy of the token for the account key.
+func (s *MemoryTokenStore) Put(ctx context.Context, key string, token *OAuth2Token) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.tokens[key] = *token
+	return nil
+}
+
+// Delete removes the token stored for the account key.
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.tokens, key)
+	return nil
+}
+
+// An OAuth2CredentialStore is a CredentialStore which signs requests on
+// behalf of a user with the OAuth 2.0 token stored for the user ID in the
+// token store. Refreshed tokens are written back to the token store.
+type OAuth2CredentialStore struct {
+	// The app config used to refresh tokens.
+	Config *OAuth2Config
+
+	// The store tokens are kept in, keyed by user ID.
+	Tokens TokenStore
+
+	// The maximum number of users whose credentials are cached. Once more
+	// users are cached, the credentials of the least recently used user are
+	// dropped, and read from the token store again when the user is next
+	// acted for. Defaults to DefaultMaxCachedUsers.
+	MaxCachedUsers int
+
+	m     sync.Mutex
+	users map[string]*list.Element
+	lru   list.List
+}
+
+// A cachedCredentials is an entry of the credentials cache of an
+// OAuth2CredentialStore.
+type cachedCredentials struct {
+	userID string
+	creds  *Credentials
+}
+
+// NewOAuth2CredentialStore returns a pointer to a new OAuth2CredentialStore.
+func NewOAuth2CredentialStore(cfg *OAuth2Config, tokens TokenStore) *OAuth2CredentialStore {
+	return &OAuth2CredentialStore{
+		Config:         cfg,
+		Tokens:         tokens,
+		MaxCachedUsers: DefaultMaxCachedUsers,
+	}
+}
+
+// Credentials returns the credentials of the user. They are cached, so the
+// token is only read from the token store once per user, unless the user's
+// credentials were dropped from the cache since.
+func (s *OAuth2CredentialStore) Credentials(ctx context.Context, userID string) (*Credentials, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if e, ok := s.users[userID]; ok {
+		s.lru.MoveToFront(e)
+		return e.Value.(*cachedCredentials).creds, nil
+	}
+	if _, err := s.Tokens.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if s.users == nil {
+		s.users = make(map[string]*list.Element)
+	}
+	creds := NewStoredOAuth2Credentials(s.Config, s.Tokens, userID)
+	s.users[userID] = s.lru.PushFront(&cachedCredentials{userID: userID, creds: creds})
+
+	max := s.MaxCachedUsers
+	if max <= 0 {
+		max = DefaultMaxCachedUsers
+	}
+	for s.lru.Len() > max {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.users, oldest.Value.(*cachedCredentials).userID)
+	}
+	return creds, nil
+}
+
+// Forget drops the cached credentials of the user, so the token is read from
+// the token store again, for example after the user authorized the app again
+// or once the app stops acting for the user.
+func (s *OAuth2CredentialStore) Forget(userID string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if e, ok := s.users[userID]; ok {
+		s.lru.Remove(e)
+		delete(s.users, userID)
+	}
+}
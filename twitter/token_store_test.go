@@ -0,0 +1,145 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTokenStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+
+	_, err := store.Get(ctx, "2244994945")
+	assert.Equal(t, ErrTokenNotFound, err)
+
+	token := &OAuth2Token{AccessToken: "access", RefreshToken: "refresh"}
+	assert.Nil(t, store.Put(ctx, "2244994945", token))
+	token.AccessToken = "modified"
+
+	stored, err := store.Get(ctx, "2244994945")
+	assert.Nil(t, err)
+	assert.Equal(t, "access", stored.AccessToken)
+
+	assert.Nil(t, store.Delete(ctx, "2244994945"))
+	_, err = store.Get(ctx, "2244994945")
+	assert.Equal(t, ErrTokenNotFound, err)
+}
+
+func (suite *twitterClientSuite) Test_StoredOAuth2CredentialsRefresh() {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+	suite.Require().Nil(store.Put(ctx, "2244994945", &OAuth2Token{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(10 * time.Second),
+	}))
+
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		suite.Require().Nil(r.ParseForm())
+		suite.Assert().Equal("refresh", r.PostForm.Get("refresh_token"))
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "refresh_token": "rotated"}`)
+	})
+	suite.mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		suite.Assert().Equal("Bearer fresh", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"data": {}}`)
+	})
+
+	suite.client.Config.WithCredentialStore(NewOAuth2CredentialStore(suite.newOAuth2Config(), store))
+	req := suite.newUserContextRequest()
+	req.ApplyOptions(WithUserID("2244994945"))
+	suite.Assert().Nil(req.Send())
+
+	stored, err := store.Get(ctx, "2244994945")
+	suite.Require().Nil(err)
+	suite.Assert().Equal("fresh", stored.AccessToken)
+	suite.Assert().Equal("rotated", stored.RefreshToken)
+
+	req = suite.newUserContextRequest()
+	req.ApplyOptions(WithUserID("783214"))
+	suite.Assert().Equal(ErrTokenNotFound, req.Send())
+}
+
+// failingTokenStore is a TokenStore whose writes fail while err is set.
+type failingTokenStore struct {
+	TokenStore
+	err error
+}
+
+func (s *failingTokenStore) Put(ctx context.Context, key string, token *OAuth2Token) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.TokenStore.Put(ctx, key, token)
+}
+
+func (suite *twitterClientSuite) Test_StoredOAuth2CredentialsStoreFailure() {
+	ctx := context.Background()
+	store := &failingTokenStore{TokenStore: NewMemoryTokenStore()}
+	suite.Require().Nil(store.Put(ctx, "2244994945", &OAuth2Token{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(10 * time.Second),
+	}))
+
+	refreshes := 0
+	suite.mux.HandleFunc("/2/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		fmt.Fprintf(w, `{"token_type": "bearer", "expires_in": 7200, "access_token": "fresh", "refresh_token": "rotated"}`)
+	})
+
+	storeErr := errors.New("disk full")
+	store.err = storeErr
+	creds := NewStoredOAuth2Credentials(suite.newOAuth2Config(), store, "2244994945")
+
+	value, err := creds.Retrieve()
+	suite.Require().Nil(err)
+	suite.Assert().Equal("fresh", value.OAuth2Token.AccessToken)
+	suite.Assert().Equal(storeErr, creds.StoreError())
+
+	// The next retrieval stores the kept token without refreshing again.
+	store.err = nil
+	value, err = creds.Retrieve()
+	suite.Require().Nil(err)
+	suite.Assert().Equal("fresh", value.OAuth2Token.AccessToken)
+	suite.Assert().Nil(creds.StoreError())
+	suite.Assert().Equal(1, refreshes)
+
+	stored, err := store.Get(ctx, "2244994945")
+	suite.Require().Nil(err)
+	suite.Assert().Equal("rotated", stored.RefreshToken)
+}
+
+func TestOAuth2CredentialStoreCache(t *testing.T) {
+	ctx := context.Background()
+	tokens := NewMemoryTokenStore()
+	for _, userID := range []string{"1", "2", "3"} {
+		assert.Nil(t, tokens.Put(ctx, userID, &OAuth2Token{AccessToken: userID}))
+	}
+	store := NewOAuth2CredentialStore(&OAuth2Config{}, tokens)
+	store.MaxCachedUsers = 2
+
+	first, _ := store.Credentials(ctx, "1")
+	second, _ := store.Credentials(ctx, "2")
+	creds, _ := store.Credentials(ctx, "1")
+	assert.True(t, first == creds)
+
+	// The least recently used user is dropped from the cache.
+	store.Credentials(ctx, "3")
+	creds, _ = store.Credentials(ctx, "2")
+	assert.False(t, second == creds)
+	creds, _ = store.Credentials(ctx, "3")
+	assert.Equal(t, 2, len(store.users))
+
+	// Forgotten users are read from the token store again.
+	store.Forget("3")
+	other, _ := store.Credentials(ctx, "3")
+	assert.False(t, creds == other)
+	assert.Equal(t, 2, len(store.users))
+	assert.Equal(t, 2, store.lru.Len())
+}